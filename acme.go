@@ -0,0 +1,233 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultACMEPropagationTimeout is used by ACMESolver when its
+// PropagationTimeout is unset.
+const DefaultACMEPropagationTimeout = 2 * time.Minute
+
+// ACMESolver solves ACME DNS-01 challenges by creating the _acme-challenge
+// TXT records they require. It is safe for concurrent use, including for
+// challenges with the same name, such as those for example.com and
+// *.example.com.
+type ACMESolver struct {
+	Client *Client
+
+	// TTL of the challenge records.
+	TTL int64
+
+	// Propagation checks that challenge records are served before Present
+	// returns. If nil, the nameservers of the record's domain are checked.
+	Propagation *PropagationChecker
+
+	// PropagationTimeout limits the wait for a challenge record to be
+	// served.
+	PropagationTimeout time.Duration
+
+	mu     sync.Mutex
+	locks  map[string]*acmeLock
+	active map[string]*acmeRecord
+}
+
+// acmeLock serializes the challenges for one name. It is dropped once no
+// challenge for the name holds or waits for it.
+type acmeLock struct {
+	sync.Mutex
+	users int
+}
+
+// acmeRecord is a challenge record that has been created. Identical
+// challenges share a record, which is deleted when the last is cleaned up.
+type acmeRecord struct {
+	domainID string
+	recordID string
+	refs     int
+}
+
+// NewACMESolver returns a solver that creates records with client.
+func NewACMESolver(client *Client) *ACMESolver {
+	return &ACMESolver{
+		Client: client,
+		TTL:    120,
+		locks:  map[string]*acmeLock{},
+		active: map[string]*acmeRecord{},
+	}
+}
+
+// ACMEChallenge returns the fully qualified name and value of the TXT
+// record that answers the DNS-01 challenge for domain.
+func ACMEChallenge(domain, keyAuth string) (fqdn, value string) {
+	domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), "*.")
+	sum := sha256.Sum256([]byte(keyAuth))
+	return "_acme-challenge." + domain, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Present creates the TXT record for a challenge, and waits until it is
+// served by the nameservers of its domain. If it is not served within the
+// PropagationTimeout, it is deleted and an error is returned.
+func (s *ACMESolver) Present(domain, token, keyAuth string) error {
+	fqdn, value := ACMEChallenge(domain, keyAuth)
+	unlock := s.lock(fqdn)
+	defer unlock()
+
+	key := fqdn + " " + value
+	if rec := s.get(key); rec != nil {
+		rec.refs++
+		return nil
+	}
+
+	zone, err := s.Client.ZoneForFQDN(fqdn)
+	if err != nil {
+		return err
+	}
+	cr := map[string]interface{}{
+		"name":  relativeName(fqdn, zone.Name),
+		"type":  "TXT",
+		"value": value,
+		"ttl":   s.TTL,
+	}
+	recordID, err := s.Client.CreateRecord(zone.StringID(), cr)
+	if err != nil {
+		return fmt.Errorf("Error presenting challenge for %s: %s", domain, err)
+	}
+	if err := s.waitForPropagation(zone.StringID(), fqdn, value); err != nil {
+		if derr := s.Client.DeleteRecord(zone.StringID(), recordID); derr != nil {
+			return fmt.Errorf("Error presenting challenge for %s: %s; unable to delete record: %s",
+				domain, err, derr)
+		}
+		return fmt.Errorf("Error presenting challenge for %s: %s", domain, err)
+	}
+	s.set(key, &acmeRecord{domainID: zone.StringID(), recordID: recordID, refs: 1})
+	return nil
+}
+
+// waitForPropagation waits until the challenge record is served, or the
+// PropagationTimeout passes.
+func (s *ACMESolver) waitForPropagation(domainID, fqdn, value string) error {
+	checker := s.Propagation
+	if checker == nil {
+		var err error
+		if checker, err = s.Client.PropagationChecker(domainID); err != nil {
+			return err
+		}
+	}
+	timeout := s.PropagationTimeout
+	if timeout <= 0 {
+		timeout = DefaultACMEPropagationTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return checker.WaitForPropagation(ctx, fqdn, "TXT", value)
+}
+
+// CleanUp deletes the TXT record created by Present for a challenge.
+func (s *ACMESolver) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := ACMEChallenge(domain, keyAuth)
+	unlock := s.lock(fqdn)
+	defer unlock()
+
+	key := fqdn + " " + value
+	rec := s.get(key)
+	if rec == nil {
+		return fmt.Errorf("No challenge record for %s", domain)
+	}
+	if rec.refs > 1 {
+		rec.refs--
+		return nil
+	}
+
+	if err := s.Client.DeleteRecord(rec.domainID, rec.recordID); err != nil {
+		return fmt.Errorf("Error cleaning up challenge for %s: %s", domain, err)
+	}
+	s.set(key, nil)
+	return nil
+}
+
+// lock locks the challenges for one name, returning the unlock function.
+// The lock of a name is deleted when its last user unlocks it, so that
+// locks are not kept for names whose challenges have been cleaned up.
+func (s *ACMESolver) lock(fqdn string) func() {
+	s.mu.Lock()
+	if s.locks == nil {
+		s.locks = map[string]*acmeLock{}
+	}
+	l, ok := s.locks[fqdn]
+	if !ok {
+		l = new(acmeLock)
+		s.locks[fqdn] = l
+	}
+	l.users++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if l.users--; l.users == 0 {
+			delete(s.locks, fqdn)
+		}
+	}
+}
+
+func (s *ACMESolver) get(key string) *acmeRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active[key]
+}
+
+func (s *ACMESolver) set(key string, rec *acmeRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec == nil {
+		delete(s.active, key)
+		return
+	}
+	if s.active == nil {
+		s.active = map[string]*acmeRecord{}
+	}
+	s.active[key] = rec
+}
+
+// ZoneForFQDN finds the managed domain that holds the name given, by
+// removing labels from the front of the name until it matches a domain.
+func (c *Client) ZoneForFQDN(fqdn string) (*Domain, error) {
+	domains, err := c.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	for {
+		for _, domain := range domains {
+			if strings.ToLower(domain.Name) == name {
+				result := domain // not pointer, so data copied
+				return &result, nil
+			}
+		}
+		i := strings.Index(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return nil, fmt.Errorf("Unable to find a managed domain for %s", fqdn)
+}
+
+// relativeName returns the name of fqdn within zone, as used by records.
+func relativeName(fqdn, zone string) string {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	if strings.EqualFold(fqdn, zone) {
+		return ""
+	}
+	return fqdn[:len(fqdn)-len(zone)-1]
+}
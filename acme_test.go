@@ -0,0 +1,158 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// serveChallenges makes the nameserver serve the records of the challenges
+// given, as domain and key authorization pairs.
+func serveChallenges(server *dnsServer, challenges ...string) {
+	var records []string
+	for i := 0; i < len(challenges); i += 2 {
+		fqdn, value := ACMEChallenge(challenges[i], challenges[i+1])
+		records = append(records, fmt.Sprintf("%s. 120 IN TXT %q", fqdn, value))
+	}
+	server.set(records...)
+}
+
+func newTestACMESolver(c *C, client *Client) (*ACMESolver, *dnsServer) {
+	server := startDNSServer(c)
+	solver := NewACMESolver(client)
+	solver.Propagation = &PropagationChecker{
+		Nameservers: []string{server.addr},
+		Interval:    10 * time.Millisecond,
+	}
+	return solver, server
+}
+
+func (s *S) Test_ACMEChallenge(c *C) {
+	fqdn, value := ACMEChallenge("*.www.example.com.", "token.thumbprint")
+	c.Assert(fqdn, Equals, "_acme-challenge.www.example.com")
+	c.Assert(value, Equals, "61rBZ_4knHblO0MNoxFsXZ_eTFUHum0B6IVRbhvUn5I")
+}
+
+func (s *S) Test_ZoneForFQDN(c *C) {
+	testServer.Response(200, nil, domainsRead)
+	domain, err := s.client.ZoneForFQDN("_acme-challenge.WWW.Example.NET.")
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(domain.Name, Equals, "example.net")
+
+	testServer.Response(200, nil, domainsRead)
+	_, err = s.client.ZoneForFQDN("example.org")
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, "Unable to find a managed domain for example.org")
+}
+
+func (s *S) Test_ACMESolverPresentCleanUp(c *C) {
+	solver, server := newTestACMESolver(c, s.client)
+	defer server.stop()
+	serveChallenges(server, "www.example.com", "key1", "*.www.example.com", "key2")
+
+	// The challenges for www.example.com and *.www.example.com share a
+	// name but not a value, so each gets its own record.
+	testServer.Response(200, nil, domainsRead)
+	testServer.Response(201, nil, `{"id":1,"type":"TXT"}`)
+	testServer.Response(200, nil, domainsRead)
+	testServer.Response(201, nil, `{"id":2,"type":"TXT"}`)
+	c.Assert(solver.Present("www.example.com", "t1", "key1"), IsNil)
+	c.Assert(solver.Present("*.www.example.com", "t2", "key2"), IsNil)
+	reqs := testServer.WaitRequests(4)
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(reqs[1].URL.Path, Equals, "/dns/managed/870073/records/")
+	c.Assert(sent["name"], Equals, "_acme-challenge.www")
+	c.Assert(sent["type"], Equals, "TXT")
+
+	// Presenting an identical challenge again does not create a record,
+	// and the record stays until both have been cleaned up.
+	c.Assert(solver.Present("www.example.com", "t1", "key1"), IsNil)
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), IsNil)
+
+	testServer.Response(200, nil, "")
+	testServer.Response(200, nil, "")
+	c.Assert(solver.CleanUp("*.www.example.com", "t2", "key2"), IsNil)
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), IsNil)
+	reqs = testServer.WaitRequests(2)
+	c.Assert(reqs[0].Method, Equals, "DELETE")
+	c.Assert(reqs[0].URL.Path, Equals, "/dns/managed/870073/records/2/")
+	c.Assert(reqs[1].URL.Path, Equals, "/dns/managed/870073/records/1/")
+
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), NotNil)
+	c.Assert(solver.locks, HasLen, 0)
+}
+
+func (s *S) Test_ACMESolverZeroValue(c *C) {
+	server := startDNSServer(c)
+	defer server.stop()
+	serveChallenges(server, "www.example.com", "key1")
+	solver := &ACMESolver{
+		Client:      s.client,
+		TTL:         60,
+		Propagation: &PropagationChecker{Nameservers: []string{server.addr}},
+	}
+
+	testServer.Response(200, nil, domainsRead)
+	testServer.Response(201, nil, `{"id":1,"type":"TXT"}`)
+	c.Assert(solver.Present("www.example.com", "t1", "key1"), IsNil)
+	_ = testServer.WaitRequests(2)
+
+	testServer.Response(200, nil, "")
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+}
+
+func (s *S) Test_ACMESolverConcurrentPresent(c *C) {
+	solver, server := newTestACMESolver(c, s.client)
+	defer server.stop()
+	serveChallenges(server, "www.example.com", "key1")
+
+	// Identical challenges presented at once share one record.
+	testServer.Response(200, nil, domainsRead)
+	testServer.Response(201, nil, `{"id":1,"type":"TXT"}`)
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = solver.Present("www.example.com", "t1", "key1")
+		}(i)
+	}
+	wg.Wait()
+	c.Assert(errs, DeepEquals, []error{nil, nil})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(reqs[1].Method, Equals, "POST")
+
+	testServer.Response(200, nil, "")
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), IsNil)
+	c.Assert(solver.CleanUp("www.example.com", "t1", "key1"), IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Method, Equals, "DELETE")
+	c.Assert(solver.locks, HasLen, 0)
+}
+
+func (s *S) Test_ACMESolverPresentNotPropagated(c *C) {
+	solver, server := newTestACMESolver(c, s.client)
+	defer server.stop()
+	solver.PropagationTimeout = 100 * time.Millisecond
+
+	// A record that is not served in time is deleted.
+	testServer.Response(200, nil, domainsRead)
+	testServer.Response(201, nil, `{"id":1,"type":"TXT"}`)
+	testServer.Response(200, nil, "")
+	err := solver.Present("www.example.com", "t1", "key1")
+	c.Assert(err, ErrorMatches, "Error presenting challenge for www.example.com: Record _acme-challenge.www.example.com. TXT not propagated to .*")
+	reqs := testServer.WaitRequests(3)
+	c.Assert(reqs[2].Method, Equals, "DELETE")
+	c.Assert(reqs[2].URL.Path, Equals, "/dns/managed/870073/records/1/")
+	c.Assert(solver.active, HasLen, 0)
+}
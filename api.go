@@ -2,6 +2,7 @@ package dnsmadeeasy
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
@@ -17,7 +18,11 @@ import (
 // SandboxURL is the URL of the DNS Made Easy Sandbox
 const SandboxURL = "http://api.sandbox.dnsmadeeasy.com/V2.0"
 
-// Client provides a client to the dnsmadeeasy API
+// Client provides a client to the dnsmadeeasy API.
+//
+// A Client is safe for concurrent use by multiple goroutines. Its fields
+// must be set before it is first used and not changed afterwards; to use
+// different settings, copy the Client.
 type Client struct {
 	// API Key
 	AKey string
@@ -31,6 +36,35 @@ type Client struct {
 	// HttpClient is the client to use. Default will be
 	// used if not provided.
 	HTTP *http.Client
+
+	// Limiter, if set, limits the rate of requests. It may be shared by
+	// several Clients to keep them under one account's limit.
+	Limiter *RateLimiter
+
+	// Cache, if set, holds record listings so that reads can share them.
+	Cache *RecordCache
+
+	// SkipValidation turns off the checks made on records before they are
+	// sent to the API.
+	SkipValidation bool
+
+	// DryRun stops the Client making changes. Requests that would change
+	// anything are built and signed but not sent, and succeed as if they
+	// had been; records and domains they create have the ID DryRunID.
+	// Reads are still made.
+	DryRun bool
+
+	// OnDryRun, if set, is given each request not sent in dry-run mode,
+	// to preview the changes. Otherwise the requests are logged.
+	OnDryRun func(req *PreparedRequest)
+
+	// Journal, if set, is written an entry for every record created,
+	// updated or deleted.
+	Journal Journal
+
+	// ctx, if set by WithContext, bounds the waits on the Limiter and the
+	// requests sent.
+	ctx context.Context
 }
 
 // Body is the body of a request
@@ -60,6 +94,14 @@ func NewClient(akey string, skey string) (*Client, error) {
 	return &client, nil
 }
 
+// WithContext returns a copy of the Client whose requests, and waits on
+// the Limiter, are abandoned when ctx is done.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	copied := *c
+	copied.ctx = ctx
+	return &copied
+}
+
 // NewRequest creates a new request with the params
 func (c *Client) NewRequest(method, path string, body *bytes.Buffer,
 	requestDate string) (*http.Request, error) {
@@ -97,6 +139,24 @@ func (c *Client) NewRequest(method, path string, body *bytes.Buffer,
 	return req, nil
 }
 
+// do sends a request, first waiting on the Limiter if there is one. In
+// dry-run mode, requests other than reads are not sent.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.DryRun && req.Method != "GET" {
+		return c.dryRun(req)
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Limiter != nil {
+		if err := c.Limiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c.HTTP.Do(req.WithContext(ctx))
+}
+
 // parseError is used to take an error json resp
 // and return a single string for use in error messages
 func parseError(resp *http.Response) error {
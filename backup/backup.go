@@ -0,0 +1,201 @@
+// Package backup dumps the managed domains of a DNS Made Easy account to
+// an archive, and restores them into the same or another account.
+//
+// An archive is a gzipped tar file holding a manifest and a JSON file for
+// each domain, with the domain's records and its SOA, vanity, template,
+// folder and transfer ACL assignments. The records include the passwords
+// of dynamic DNS records, so archives should be stored as secrets.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"time"
+)
+
+// Version is the version of the archive format written by this package.
+const Version = 1
+
+const (
+	manifestFile = "manifest.json"
+	domainDir    = "domains"
+)
+
+// Archive is a backup of the domains of an account.
+type Archive struct {
+	Created time.Time
+	Domains []Domain
+}
+
+// Domain is the backup of one domain.
+type Domain struct {
+	Domain  dme.Domain   `json:"domain"`
+	Records []dme.Record `json:"records"`
+}
+
+// manifest is the first file of an archive.
+type manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Domains []string  `json:"domains"`
+}
+
+// Dump backs up the domains of the account with the names given, or every
+// domain if no names are given.
+func Dump(c *dme.Client, names ...string) (*Archive, error) {
+	domains, err := c.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	a := &Archive{Created: time.Now().UTC()}
+	for _, d := range domains {
+		if len(names) > 0 && !wanted[d.Name] {
+			continue
+		}
+		delete(wanted, d.Name)
+
+		// The listing may leave out the assignments, so each domain is
+		// read in full.
+		domain, err := c.ReadDomain(d.StringID())
+		if err != nil {
+			return nil, fmt.Errorf("Error backing up %s: %s", d.Name, err)
+		}
+		records, err := c.ListRecords(d.StringID())
+		if err != nil {
+			return nil, fmt.Errorf("Error backing up %s: %s", d.Name, err)
+		}
+		a.Domains = append(a.Domains, Domain{Domain: *domain, Records: records})
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("Unable to find domain %q", name)
+	}
+
+	sort.Slice(a.Domains, func(i, j int) bool {
+		return a.Domains[i].Domain.Name < a.Domains[j].Domain.Name
+	})
+	return a, nil
+}
+
+// Filename returns a name for the archive holding the time it was made,
+// such as dme-backup-20150102T150405Z.tar.gz.
+func (a *Archive) Filename() string {
+	return "dme-backup-" + a.Created.UTC().Format("20060102T150405Z") + ".tar.gz"
+}
+
+// Domain returns the backup of the domain with the name given, or nil.
+func (a *Archive) Domain(name string) *Domain {
+	for i := range a.Domains {
+		if a.Domains[i].Domain.Name == name {
+			return &a.Domains[i]
+		}
+	}
+	return nil
+}
+
+// Write writes the archive to w as a gzipped tar file.
+func (a *Archive) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	m := manifest{Version: Version, Created: a.Created}
+	for _, d := range a.Domains {
+		m.Domains = append(m.Domains, d.Domain.Name)
+	}
+	if err := writeJSON(tw, manifestFile, a.Created, m); err != nil {
+		return err
+	}
+	for _, d := range a.Domains {
+		name := path.Join(domainDir, d.Domain.Name+".json")
+		if err := writeJSON(tw, name, a.Created, d); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeJSON(tw *tar.Writer, name string, modTime time.Time, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// Read reads an archive written by Write.
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading archive: %s", err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading archive: %s", err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading archive: %s", err)
+		}
+		files[hdr.Name] = data
+	}
+
+	data, ok := files[manifestFile]
+	if !ok {
+		return nil, fmt.Errorf("Archive has no %s", manifestFile)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", manifestFile, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("Unsupported archive version %d", m.Version)
+	}
+
+	a := &Archive{Created: m.Created}
+	for _, name := range m.Domains {
+		file := path.Join(domainDir, name+".json")
+		data, ok := files[file]
+		if !ok {
+			return nil, fmt.Errorf("Archive has no %s", file)
+		}
+		var d Domain
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %s", file, err)
+		}
+		a.Domains = append(a.Domains, d)
+	}
+	return a, nil
+}
@@ -0,0 +1,194 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/testutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// addDomain adds a domain with the assignments and records of d.
+func addDomain(api *testutil.FakeAPI, d dme.Domain, records ...dme.Record) int64 {
+	id := api.AddDomain(d.Name)
+	fields := map[string]interface{}{"gtdEnabled": d.GtdEnabled}
+	for name, value := range d.Assignments() {
+		fields[name] = value
+	}
+	api.SetDomain(id, fields)
+	for _, r := range records {
+		api.AddRecord(id, r)
+	}
+	return id
+}
+
+func client(api *testutil.FakeAPI) *dme.Client {
+	c, _ := dme.NewClient("akey", "skey")
+	c.URL = api.URL
+	return c
+}
+
+// writes returns the number of changes requested since it was last
+// called.
+func writes(api *testutil.FakeAPI) int {
+	n := 0
+	for _, req := range api.Requests() {
+		if !strings.HasPrefix(req, "GET") {
+			n++
+		}
+	}
+	return n
+}
+
+var exampleRecords = []dme.Record{
+	{Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300, Source: 1, GtdLocation: "DEFAULT"},
+	{Name: "", Type: "MX", Value: "mail", TTL: 86400, MXLevel: 10, Source: 1, GtdLocation: "DEFAULT"},
+	{Name: "tpl", Type: "A", Value: "3.3.3.3", TTL: 300, Source: 0, GtdLocation: "DEFAULT"},
+}
+
+func TestDumpWriteRead(t *testing.T) {
+	account := testutil.NewFakeAPI()
+	defer account.Close()
+	addDomain(account, dme.Domain{Name: "example.com", SOAID: 7, TemplateID: 9}, exampleRecords...)
+	addDomain(account, dme.Domain{Name: "example.net", GtdEnabled: true})
+
+	archive, err := Dump(client(account))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(archive.Domains) != 2 || archive.Domains[0].Domain.Name != "example.com" {
+		t.Fatalf("bad domains: %#v", archive.Domains)
+	}
+	if d := archive.Domains[0].Domain; d.SOAID != 7 || d.TemplateID != 9 {
+		t.Fatalf("assignments not backed up: %#v", d)
+	}
+	if !strings.HasPrefix(archive.Filename(), "dme-backup-") {
+		t.Fatalf("bad filename: %s", archive.Filename())
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := archive.Write(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	back, err := Read(buf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !back.Created.Equal(archive.Created) {
+		t.Fatalf("bad created time: %v", back.Created)
+	}
+	back.Created = archive.Created
+	if !reflect.DeepEqual(back, archive) {
+		t.Fatalf("archive changed by write and read:\n%#v\n%#v", back, archive)
+	}
+
+	if _, err := Dump(client(account), "example.org"); err == nil {
+		t.Fatalf("expected an error for a missing domain")
+	}
+}
+
+func TestRestoreIntoEmptyAccount(t *testing.T) {
+	source := testutil.NewFakeAPI()
+	defer source.Close()
+	addDomain(source, dme.Domain{Name: "example.com", SOAID: 7, TemplateID: 9}, exampleRecords...)
+	archive, err := Dump(client(source))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	target := testutil.NewFakeAPI()
+	defer target.Close()
+
+	// A dry run reports the changes but makes none.
+	results, err := Restore(client(target), archive, Options{DryRun: true, SkipAssignments: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if n := writes(target); n != 0 {
+		t.Fatalf("dry run made %d changes", n)
+	}
+	report := bytes.NewBuffer(nil)
+	WriteReport(report, results)
+	expected := `example.com: create domain
+example.com: create www A 1.1.1.1
+example.com: create @ MX mail
+example.com: create tpl A 3.3.3.3
+example.com: 3 created, 0 deleted, 0 unchanged
+`
+	if report.String() != expected {
+		t.Fatalf("bad report:\n%s", report)
+	}
+
+	// Without the template, its records are restored as records of the
+	// domain.
+	if _, err := Restore(client(target), archive, Options{SkipAssignments: true}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	id, ok := target.DomainID("example.com")
+	var d dme.Domain
+	if !ok || target.Domain(id, &d) != nil || d.SOAID != 0 || d.TemplateID != 0 {
+		t.Fatalf("bad domain: %#v", d)
+	}
+	if records := target.Records(id); len(records) != 3 {
+		t.Fatalf("bad records: %#v", records)
+	}
+
+	// Restoring again changes nothing.
+	writes(target)
+	results, err = Restore(client(target), archive, Options{SkipAssignments: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if writes(target) != 0 || results[0].Unchanged != 3 {
+		t.Fatalf("second restore made changes: %#v", results)
+	}
+}
+
+func TestRestoreAssignmentsAndPrune(t *testing.T) {
+	account := testutil.NewFakeAPI()
+	defer account.Close()
+	id := addDomain(account, dme.Domain{Name: "example.com", SOAID: 7, TemplateID: 9}, exampleRecords...)
+	archive, err := Dump(client(account))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The domain drifts from the backup.
+	account.SetDomain(id, map[string]interface{}{"soaId": 0, "gtdEnabled": true})
+	account.RemoveRecord(id, account.Records(id)[0]["id"].(int64))
+	account.AddRecord(id, dme.Record{Name: "extra", Type: "CNAME", Value: "www", Source: 1})
+
+	results, err := Restore(client(account), archive, Options{Domains: []string{"example.com"}, Prune: true})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	report := bytes.NewBuffer(nil)
+	WriteReport(report, results)
+	expected := `example.com: set gtdEnabled to false
+example.com: set soaId to 7
+example.com: create www A 1.1.1.1
+example.com: delete extra CNAME www
+example.com: 1 created, 1 deleted, 1 unchanged
+`
+	if report.String() != expected {
+		t.Fatalf("bad report:\n%s", report)
+	}
+
+	var d dme.Domain
+	if err := account.Domain(id, &d); err != nil || d.SOAID != 7 || d.TemplateID != 9 || d.GtdEnabled {
+		t.Fatalf("bad domain: %#v %v", d, err)
+	}
+	var values []string
+	for _, r := range account.Records(id) {
+		values = append(values, fmt.Sprintf("%s/%s", r["name"], r["type"]))
+	}
+	if strings.Join(values, " ") != "/MX tpl/A www/A" {
+		t.Fatalf("bad records: %v", values)
+	}
+
+	if _, err := Restore(client(account), archive, Options{Domains: []string{"example.org"}}); err == nil {
+		t.Fatalf("expected an error for a domain not in the archive")
+	}
+}
@@ -0,0 +1,244 @@
+package backup
+
+import (
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"io"
+	"sort"
+)
+
+// Options configure Restore.
+type Options struct {
+	// Domains are the names of the domains to restore. All the domains of
+	// the archive are restored if none are given.
+	Domains []string
+
+	// DryRun reports the changes a restore would make without making
+	// them.
+	DryRun bool
+
+	// Prune deletes records of the account's domains that are not in the
+	// archive, so that the domains match it exactly.
+	Prune bool
+
+	// SkipAssignments leaves out the SOA, vanity, template, folder and
+	// transfer ACL assignments. Their IDs belong to the account the
+	// archive was made from, so they should be skipped when restoring
+	// into another account.
+	SkipAssignments bool
+}
+
+// Result is what Restore did to one domain, or would do in a dry run.
+type Result struct {
+	Name     string
+	DomainID string
+
+	// CreatedDomain is set if the domain did not exist in the account.
+	CreatedDomain bool
+
+	// Updated holds the domain fields set, such as the assignments.
+	Updated map[string]interface{}
+
+	Created   []dme.Record
+	Deleted   []dme.Record
+	Unchanged int
+}
+
+// Restore restores the domains of the archive into the account of the
+// client. Domains missing from the account are created, and records
+// missing from a domain are added. Records that are already present are
+// left alone, as are other records unless opts.Prune is set.
+//
+// Restore stops at the first error, returning the results so far.
+func Restore(c *dme.Client, a *Archive, opts Options) ([]Result, error) {
+	backups := a.Domains
+	if len(opts.Domains) > 0 {
+		backups = nil
+		for _, name := range opts.Domains {
+			b := a.Domain(name)
+			if b == nil {
+				return nil, fmt.Errorf("Archive has no domain %q", name)
+			}
+			backups = append(backups, *b)
+		}
+	}
+
+	domains, err := c.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]dme.Domain{}
+	for _, d := range domains {
+		existing[d.Name] = d
+	}
+
+	var results []Result
+	for i := range backups {
+		current, ok := existing[backups[i].Domain.Name]
+		var currentPtr *dme.Domain
+		if ok {
+			currentPtr = &current
+		}
+		result, err := restoreDomain(c, &backups[i], currentPtr, opts)
+		if result != nil {
+			results = append(results, *result)
+		}
+		if err != nil {
+			return results, fmt.Errorf("Error restoring %s: %s", backups[i].Domain.Name, err)
+		}
+	}
+	return results, nil
+}
+
+// restoreDomain restores one domain, which exists in the account as
+// current, or is missing if current is nil.
+func restoreDomain(c *dme.Client, b *Domain, current *dme.Domain, opts Options) (*Result, error) {
+	result := &Result{Name: b.Domain.Name}
+
+	var records []dme.Record
+	if current == nil {
+		result.CreatedDomain = true
+		current = &dme.Domain{Name: b.Domain.Name}
+		if !opts.DryRun {
+			created, err := c.CreateDomain(b.Domain.Name)
+			if err != nil {
+				return result, err
+			}
+			current = created
+			result.DomainID = created.StringID()
+		}
+	} else {
+		// A full read has the current assignments, which the listing may
+		// leave out.
+		full, err := c.ReadDomain(current.StringID())
+		if err != nil {
+			return result, err
+		}
+		current = full
+		result.DomainID = current.StringID()
+		records, err = c.ListRecords(result.DomainID)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Updated = domainChanges(&b.Domain, current, opts)
+	if len(result.Updated) > 0 && !opts.DryRun {
+		if err := c.UpdateDomain(result.DomainID, result.Updated); err != nil {
+			return result, err
+		}
+	}
+
+	// Records from a template are put back by assigning the template, so
+	// they are neither created nor pruned.
+	fromTemplate := func(r *dme.Record) bool {
+		return !opts.SkipAssignments && b.Domain.TemplateID != 0 &&
+			r.Source == dme.TemplateSource
+	}
+
+	matched := make([]bool, len(records))
+	for i := range b.Records {
+		r := &b.Records[i]
+		if fromTemplate(r) {
+			continue
+		}
+		found := false
+		for j := range records {
+			if !matched[j] && dme.SameContent(&records[j], r) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if found {
+			result.Unchanged++
+			continue
+		}
+		if !opts.DryRun {
+			if _, err := c.CreateRecord(result.DomainID, r.Fields()); err != nil {
+				return result, err
+			}
+		}
+		result.Created = append(result.Created, *r)
+	}
+
+	if !opts.Prune {
+		return result, nil
+	}
+	for j := range records {
+		r := &records[j]
+		if matched[j] || fromTemplate(r) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := c.DeleteRecord(result.DomainID, r.StringRecordID()); err != nil {
+				return result, err
+			}
+		}
+		result.Deleted = append(result.Deleted, *r)
+	}
+	return result, nil
+}
+
+// domainChanges returns the domain fields that differ between the backup
+// and the current domain, in the form given to UpdateDomain.
+func domainChanges(backup, current *dme.Domain, opts Options) map[string]interface{} {
+	changes := map[string]interface{}{}
+	if backup.GtdEnabled != current.GtdEnabled {
+		changes["gtdEnabled"] = backup.GtdEnabled
+	}
+	if opts.SkipAssignments {
+		return changes
+	}
+	have := current.Assignments()
+	for name, id := range backup.Assignments() {
+		if have[name] != id {
+			changes[name] = id
+		}
+	}
+	return changes
+}
+
+// WriteReport writes a summary of restore results to w, one line per
+// change.
+func WriteReport(w io.Writer, results []Result) error {
+	for _, r := range results {
+		if r.CreatedDomain {
+			if _, err := fmt.Fprintf(w, "%s: create domain\n", r.Name); err != nil {
+				return err
+			}
+		}
+		var names []string
+		for name := range r.Updated {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s: set %s to %v\n", r.Name, name, r.Updated[name]); err != nil {
+				return err
+			}
+		}
+		for _, rec := range r.Created {
+			if _, err := fmt.Fprintf(w, "%s: create %s\n", r.Name, describe(&rec)); err != nil {
+				return err
+			}
+		}
+		for _, rec := range r.Deleted {
+			if _, err := fmt.Fprintf(w, "%s: delete %s\n", r.Name, describe(&rec)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %d created, %d deleted, %d unchanged\n",
+			r.Name, len(r.Created), len(r.Deleted), r.Unchanged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describe(r *dme.Record) string {
+	name := r.Name
+	if name == "" {
+		name = "@"
+	}
+	return fmt.Sprintf("%s %s %s", name, r.Type, r.Value)
+}
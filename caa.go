@@ -0,0 +1,137 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Tags of a CAA record.
+const (
+	CAAIssue     = "issue"
+	CAAIssueWild = "issuewild"
+	CAAIodef     = "iodef"
+)
+
+// CAACritical is the issuer critical flag of a CAA record.
+const CAACritical int64 = 128
+
+// CAA is a Certification Authority Authorization record. It is a typed
+// view of the CAA fields of a Record.
+type CAA struct {
+	Name string
+
+	// Critical sets the issuer critical flag, which tells a CA that does
+	// not understand the tag that it must not issue.
+	Critical bool
+
+	// Tag is one of CAAIssue, CAAIssueWild or CAAIodef.
+	Tag string
+
+	// Value is the issuer domain, optionally followed by parameters, for
+	// the issue tags, or the URL incidents are reported to for iodef. It
+	// is held without quotes.
+	Value string
+
+	TTL int64
+}
+
+// NewCAA returns a CAA record for name with the tag and value specified.
+func NewCAA(name, tag, value string) *CAA {
+	return &CAA{
+		Name:  name,
+		Tag:   tag,
+		Value: value,
+		TTL:   86400,
+	}
+}
+
+// CAAFromRecord returns the CAA record held in a Record.
+func CAAFromRecord(r *Record) (*CAA, error) {
+	if r.Type != "CAA" {
+		return nil, fmt.Errorf("Record %s is of type %s, not CAA",
+			r.StringRecordID(), r.Type)
+	}
+	value := r.Value
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return &CAA{
+		Name:     r.Name,
+		Critical: r.IssuerCritical&CAACritical != 0,
+		Tag:      r.CaaType,
+		Value:    value,
+		TTL:      r.TTL,
+	}, nil
+}
+
+// Validate checks the tag is known and the value is of the form the tag
+// requires.
+func (c *CAA) Validate() error {
+	switch c.Tag {
+	case CAAIssue, CAAIssueWild:
+		// An empty issuer, written as ";", forbids all issuance.
+		issuer := strings.TrimSpace(strings.SplitN(c.Value, ";", 2)[0])
+		if issuer == "" {
+			if strings.TrimSpace(c.Value) == "" {
+				return fmt.Errorf("Invalid CAA value: an issuer or \";\" is required")
+			}
+			return nil
+		}
+		if err := validateHostname(issuer); err != nil {
+			return fmt.Errorf("Invalid CAA issuer: %s", err)
+		}
+	case CAAIodef:
+		u, err := url.Parse(c.Value)
+		if err != nil {
+			return fmt.Errorf("Invalid CAA iodef URL %q: %s", c.Value, err)
+		}
+		if u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("Invalid CAA iodef URL %q: must be a mailto, "+
+				"http or https URL", c.Value)
+		}
+	default:
+		return fmt.Errorf("Invalid CAA tag %q", c.Tag)
+	}
+	return nil
+}
+
+// Flags returns the flags field of the record.
+func (c *CAA) Flags() int64 {
+	if c.Critical {
+		return CAACritical
+	}
+	return 0
+}
+
+// Fields returns the CAA record as the fields of a Record, suitable for
+// CreateRecord.
+func (c *CAA) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"name":           c.Name,
+		"type":           "CAA",
+		"value":          fmt.Sprintf("%q", c.Value),
+		"caaType":        c.Tag,
+		"issuerCritical": c.Flags(),
+		"ttl":            c.TTL,
+	}
+}
+
+// String returns the record in BIND zone file format, for example
+//
+//	@ 86400 IN CAA 0 issue "letsencrypt.org"
+func (c *CAA) String() string {
+	name := c.Name
+	if isApex(name) {
+		name = "@"
+	}
+	return fmt.Sprintf("%s %d IN CAA %d %s %q", name, c.TTL, c.Flags(), c.Tag, c.Value)
+}
+
+// CreateCAA validates and creates a CAA record, returning its ID.
+func (c *Client) CreateCAA(domainID string, caa *CAA) (string, error) {
+	if err := caa.Validate(); err != nil {
+		return "", err
+	}
+	return c.CreateRecord(domainID, caa.Fields())
+}
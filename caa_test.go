@@ -0,0 +1,67 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_CAAFromRecord(c *C) {
+	var record Record
+	c.Assert(json.Unmarshal([]byte(caaRead), &record), IsNil)
+	c.Assert(record.CaaType, Equals, "issuewild")
+	c.Assert(record.IssuerCritical, Equals, int64(128))
+
+	caa, err := CAAFromRecord(&record)
+	c.Assert(err, IsNil)
+	c.Assert(caa.Validate(), IsNil)
+	c.Assert(caa.Critical, Equals, true)
+	c.Assert(caa.Value, Equals, "letsencrypt.org; validationmethods=dns-01")
+	c.Assert(caa.String(), Equals,
+		`@ 3600 IN CAA 128 issuewild "letsencrypt.org; validationmethods=dns-01"`)
+}
+
+func (s *S) Test_CAAValidate(c *C) {
+	c.Assert(NewCAA("", CAAIssue, ";").Validate(), IsNil)
+	c.Assert(NewCAA("", CAAIodef, "mailto:security@example.com").Validate(), IsNil)
+	c.Assert(NewCAA("", CAAIodef, "security@example.com").Validate(), ErrorMatches,
+		"Invalid CAA iodef URL.*")
+	c.Assert(NewCAA("", CAAIssue, "").Validate(), NotNil)
+	c.Assert(NewCAA("", CAAIssue, "bad ca").Validate(), ErrorMatches,
+		"Invalid CAA issuer.*")
+	c.Assert(NewCAA("", "contactemail", "a@example.com").Validate(), ErrorMatches,
+		`Invalid CAA tag "contactemail"`)
+}
+
+func (s *S) Test_CreateCAAGood(c *C) {
+	testServer.Response(201, nil, caaRead)
+	id, err := s.client.CreateCAA("870073", NewCAA("", CAAIssue, "letsencrypt.org"))
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "10039600")
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent["caaType"], Equals, "issue")
+	c.Assert(sent["issuerCritical"], Equals, float64(0))
+	c.Assert(sent["value"], Equals, `"letsencrypt.org"`)
+}
+
+var caaRead = `{
+  "name":"",
+  "value":"\"letsencrypt.org; validationmethods=dns-01\"",
+  "id":10039600,
+  "type":"CAA",
+  "source":1,
+  "failover":false,
+  "monitor":false,
+  "sourceId":870073,
+  "dynamicDns":false,
+  "failed":false,
+  "gtdLocation":"DEFAULT",
+  "hardLink":false,
+  "ttl":3600,
+  "issuerCritical":128,
+  "caaType":"issuewild"
+}`
@@ -0,0 +1,120 @@
+package dnsmadeeasy
+
+import (
+	"sync"
+	"time"
+)
+
+// RecordCache holds the listing of each domain's records for a short time,
+// so that a run of reads costs one request per domain rather than one per
+// read. Changes made through the Client invalidate the domain changed;
+// changes made elsewhere are seen once the listing expires.
+type RecordCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+
+	// Each invalidation moves a domain on a generation, so that a listing
+	// made before it is not stored after it.
+	generations map[string]uint64
+	epoch       uint64
+}
+
+type cacheEntry struct {
+	records []Record
+	fetched time.Time
+}
+
+// cacheGeneration identifies the invalidations of a domain seen so far.
+type cacheGeneration struct {
+	epoch, domain uint64
+}
+
+// NewRecordCache returns a cache that keeps listings for ttl.
+func NewRecordCache(ttl time.Duration) *RecordCache {
+	return &RecordCache{
+		TTL:     ttl,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns a copy of the cached records of a domain, if they have not
+// expired, so that callers may change them.
+func (rc *RecordCache) get(domainID string) ([]Record, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[domainID]
+	if !ok || time.Since(entry.fetched) > rc.TTL {
+		return nil, false
+	}
+	return append([]Record(nil), entry.records...), true
+}
+
+// generation returns the generation of a domain, to be given to put.
+func (rc *RecordCache) generation(domainID string) cacheGeneration {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return cacheGeneration{rc.epoch, rc.generations[domainID]}
+}
+
+// put stores the records of a domain, listed at the generation given. They
+// are not stored if the domain has been invalidated since.
+func (rc *RecordCache) put(domainID string, gen cacheGeneration, records []Record) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if gen != (cacheGeneration{rc.epoch, rc.generations[domainID]}) {
+		return
+	}
+	if rc.entries == nil {
+		rc.entries = map[string]cacheEntry{}
+	}
+	records = append([]Record(nil), records...)
+	rc.entries[domainID] = cacheEntry{records: records, fetched: time.Now()}
+}
+
+// Invalidate drops the listing of a domain.
+func (rc *RecordCache) Invalidate(domainID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.entries, domainID)
+	if rc.generations == nil {
+		rc.generations = map[string]uint64{}
+	}
+	rc.generations[domainID]++
+}
+
+// InvalidateAll drops every listing.
+func (rc *RecordCache) InvalidateAll() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = map[string]cacheEntry{}
+	rc.epoch++
+}
+
+// cachedRecords returns all the records of a domain, from the cache if
+// the Client has one.
+func (c *Client) cachedRecords(domainID string) ([]Record, error) {
+	var gen cacheGeneration
+	if c.Cache != nil {
+		if records, ok := c.Cache.get(domainID); ok {
+			return records, nil
+		}
+		gen = c.Cache.generation(domainID)
+	}
+	records, err := c.listRecords(domainID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Cache != nil {
+		c.Cache.put(domainID, gen, records)
+	}
+	return records, nil
+}
+
+// invalidate drops the cached listing of a domain after it is changed.
+func (c *Client) invalidate(domainID string) {
+	if c.Cache != nil {
+		c.Cache.Invalidate(domainID)
+	}
+}
@@ -0,0 +1,102 @@
+package dnsmadeeasy
+
+import (
+	. "github.com/motain/gocheck"
+	"time"
+)
+
+func (s *S) Test_ReadRecordCached(c *C) {
+	client := *s.client
+	client.Cache = NewRecordCache(time.Minute)
+
+	// Reads of any record in the domain share one listing.
+	testServer.Response(200, nil, recordRead)
+	for _, id := range []string{"10039428", "10039429", "10039428"} {
+		record, err := client.ReadRecord("870073", id)
+		c.Assert(err, IsNil)
+		c.Assert(record.StringRecordID(), Equals, id)
+	}
+	records, err := client.FindRecords("870073", "test", "A")
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	_ = testServer.WaitRequest()
+
+	// Changing the domain drops its listing.
+	testServer.Response(200, nil, "")
+	c.Assert(client.DeleteRecord("870073", "10039428"), IsNil)
+	_ = testServer.WaitRequest()
+
+	testServer.Response(200, nil, recordRead)
+	_, err = client.ReadRecord("870073", "10039429")
+	c.Assert(err, IsNil)
+	_ = testServer.WaitRequest()
+}
+
+func (s *S) Test_RecordCacheCopies(c *C) {
+	client := *s.client
+	client.Cache = NewRecordCache(time.Minute)
+
+	testServer.Response(200, nil, recordRead)
+	records, err := client.ListRecords("870073")
+	c.Assert(err, IsNil)
+	_ = testServer.WaitRequest()
+	value := records[0].Value
+
+	// Changing the records listed does not change those cached.
+	records[0].Value = "changed"
+	again, err := client.ListRecords("870073")
+	c.Assert(err, IsNil)
+	again[0].Value = "changed again"
+	record, err := client.ReadRecord("870073", records[0].StringRecordID())
+	c.Assert(err, IsNil)
+	c.Assert(record.Value, Equals, value)
+}
+
+func (s *S) Test_RecordCacheExpires(c *C) {
+	cache := NewRecordCache(time.Millisecond)
+	cache.put("1", cache.generation("1"), []Record{{RecordID: 1}})
+	_, ok := cache.get("1")
+	c.Assert(ok, Equals, true)
+
+	time.Sleep(2 * time.Millisecond)
+	_, ok = cache.get("1")
+	c.Assert(ok, Equals, false)
+
+	cache.put("1", cache.generation("1"), nil)
+	cache.InvalidateAll()
+	_, ok = cache.get("1")
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) Test_ReadRecordWithHint(c *C) {
+	testServer.Response(200, nil, recordRead)
+	record, err := s.client.ReadRecordWithHint("870073", "10039429", "test", "A")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(record.Value, Equals, "1.1.1.2")
+	c.Assert(req.URL.RawQuery, Equals, "recordName=test&type=A")
+}
+
+func (s *S) Test_RecordCacheSkipsStaleListings(c *C) {
+	cache := NewRecordCache(time.Minute)
+
+	// A listing made before an invalidation is not stored after it.
+	gen := cache.generation("1")
+	cache.Invalidate("1")
+	cache.put("1", gen, []Record{{RecordID: 1}})
+	_, ok := cache.get("1")
+	c.Assert(ok, Equals, false)
+
+	gen = cache.generation("1")
+	cache.InvalidateAll()
+	cache.put("1", gen, []Record{{RecordID: 1}})
+	_, ok = cache.get("1")
+	c.Assert(ok, Equals, false)
+
+	// Other domains are not affected.
+	gen = cache.generation("2")
+	cache.Invalidate("1")
+	cache.put("2", gen, []Record{{RecordID: 2}})
+	_, ok = cache.get("2")
+	c.Assert(ok, Equals, true)
+}
@@ -0,0 +1,200 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ChangeAction is the kind of a Change.
+type ChangeAction int
+
+// Actions of a Change.
+const (
+	ChangeCreate ChangeAction = iota
+	ChangeUpdate
+	ChangeDelete
+)
+
+func (a ChangeAction) String() string {
+	switch a {
+	case ChangeUpdate:
+		return "update"
+	case ChangeDelete:
+		return "delete"
+	}
+	return "create"
+}
+
+// Change is a record change to be applied by a ChangeSet. Fields are the
+// fields of a create or update, as given to CreateRecord or UpdateRecord,
+// and RecordID is the record updated or deleted.
+type Change struct {
+	Action   ChangeAction
+	RecordID string
+	Fields   map[string]interface{}
+}
+
+// ChangeSet applies record changes to a domain, remembering how to undo
+// each one, so that they can be rolled back as a whole. A ChangeSet is safe
+// for concurrent use, though changes are undone in the order they were
+// made, which is only well defined for changes made in sequence.
+type ChangeSet struct {
+	Client   *Client
+	DomainID string
+
+	mu   sync.Mutex
+	undo []undoOp
+
+	// ids maps the IDs of records deleted and recreated by a rollback to
+	// the IDs of the records recreated in their place.
+	ids map[string]string
+}
+
+// undoOp undoes a change, looking up record IDs in the map of recreated
+// records.
+type undoOp struct {
+	desc string
+	run  func(ids map[string]string) error
+}
+
+// NewChangeSet returns an empty ChangeSet for the domain.
+func (c *Client) NewChangeSet(domainID string) *ChangeSet {
+	return &ChangeSet{Client: c, DomainID: domainID}
+}
+
+func (cs *ChangeSet) push(desc string, run func(ids map[string]string) error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.undo = append(cs.undo, undoOp{desc, run})
+}
+
+// Len returns the number of changes that would be undone by Rollback.
+func (cs *ChangeSet) Len() int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return len(cs.undo)
+}
+
+// Create creates a record, to be deleted on rollback.
+func (cs *ChangeSet) Create(cr map[string]interface{}) (string, error) {
+	id, err := cs.Client.CreateRecord(cs.DomainID, cr)
+	if err != nil {
+		return "", err
+	}
+	cs.push("delete created record "+id, func(ids map[string]string) error {
+		return cs.Client.DeleteRecord(cs.DomainID, mappedID(ids, id))
+	})
+	return id, nil
+}
+
+// Update updates a record, to be put back as it was on rollback. Like
+// Client.UpdateRecord, it reads the record from a listing of the domain.
+func (cs *ChangeSet) Update(recordID string, cr map[string]interface{}) error {
+	before, err := cs.Client.updateRecord(cs.DomainID, recordID, nil, cr)
+	if err != nil {
+		return err
+	}
+	cs.push("restore updated record "+recordID, func(ids map[string]string) error {
+		id := mappedID(ids, recordID)
+		restored := *before
+		restored.RecordID, _ = strconv.ParseInt(id, 10, 64)
+		current, err := cs.Client.ReadRecord(cs.DomainID, id)
+		if err != nil {
+			return err
+		}
+		return cs.Client.putRecord(cs.DomainID, id, current, &restored)
+	})
+	return nil
+}
+
+// Delete deletes a record, to be recreated on rollback. The recreated
+// record has a new ID.
+func (cs *ChangeSet) Delete(recordID string) error {
+	before, err := cs.Client.ReadRecord(cs.DomainID, recordID)
+	if err != nil {
+		return err
+	}
+	return cs.deleteRecord(before)
+}
+
+// deleteRecord is Delete, for a record already read.
+func (cs *ChangeSet) deleteRecord(before *Record) error {
+	recordID := before.StringRecordID()
+	if err := cs.Client.DeleteRecord(cs.DomainID, recordID); err != nil {
+		return err
+	}
+	cs.push("recreate deleted record "+recordID, func(ids map[string]string) error {
+		id, err := cs.Client.CreateRecord(cs.DomainID, before.Fields())
+		if err != nil {
+			return err
+		}
+		ids[recordID] = id
+		return nil
+	})
+	return nil
+}
+
+// Apply applies the changes in order. If one fails, every change made by
+// the ChangeSet is rolled back, and the error returned says whether the
+// rollback succeeded.
+func (cs *ChangeSet) Apply(changes ...Change) error {
+	for i, change := range changes {
+		var err error
+		switch change.Action {
+		case ChangeCreate:
+			_, err = cs.Create(change.Fields)
+		case ChangeUpdate:
+			err = cs.Update(change.RecordID, change.Fields)
+		case ChangeDelete:
+			err = cs.Delete(change.RecordID)
+		default:
+			err = fmt.Errorf("Unknown change action %d", change.Action)
+		}
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("Error applying change %d (%s): %s", i, change.Action, err)
+		if rerr := cs.Rollback(); rerr != nil {
+			return fmt.Errorf("%s; rollback failed: %s", err, rerr)
+		}
+		return fmt.Errorf("%s; changes rolled back", err)
+	}
+	return nil
+}
+
+// Rollback undoes the changes made by the ChangeSet, most recent first.
+// It stops at the first change that cannot be undone, which is left with
+// those before it to be undone by calling Rollback again.
+func (cs *ChangeSet) Rollback() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.ids == nil {
+		cs.ids = map[string]string{}
+	}
+	for len(cs.undo) > 0 {
+		op := cs.undo[len(cs.undo)-1]
+		if err := op.run(cs.ids); err != nil {
+			return fmt.Errorf("Unable to %s: %s", op.desc, err)
+		}
+		cs.undo = cs.undo[:len(cs.undo)-1]
+	}
+	return nil
+}
+
+// Commit forgets the changes made, so that they are no longer rolled
+// back.
+func (cs *ChangeSet) Commit() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.undo = nil
+}
+
+func mappedID(ids map[string]string, id string) string {
+	if mapped, ok := ids[id]; ok {
+		return mapped
+	}
+	return id
+}
@@ -0,0 +1,73 @@
+package dnsmadeeasy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChangeSet_ApplyRollsBackOnFailure(t *testing.T) {
+	api, client, domainID := newFakeDomain(t,
+		Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300},
+		Record{RecordID: 2, Name: "mail", Type: "A", Value: "2.2.2.2", TTL: 300},
+	)
+	defer api.Close()
+	before := fakeContents(api, domainID)
+
+	cs := client.NewChangeSet(domainID)
+	err := cs.Apply(
+		Change{Action: ChangeCreate, Fields: map[string]interface{}{"name": "new", "type": "A", "value": "3.3.3.3"}},
+		Change{Action: ChangeUpdate, RecordID: "1", Fields: map[string]interface{}{"value": "4.4.4.4"}},
+		Change{Action: ChangeDelete, RecordID: "2"},
+		Change{Action: ChangeCreate, Fields: map[string]interface{}{"name": "bad", "type": "A", "value": "not-an-address"}},
+	)
+	if err == nil || !strings.HasPrefix(err.Error(), "Error applying change 3 (create)") ||
+		!strings.HasSuffix(err.Error(), "changes rolled back") {
+		t.Fatalf("bad error: %v", err)
+	}
+	if after := fakeContents(api, domainID); after != before {
+		t.Fatalf("changes not rolled back:\n%s\n%s", after, before)
+	}
+	if cs.Len() != 0 {
+		t.Fatalf("changes left to roll back: %d", cs.Len())
+	}
+}
+
+func TestChangeSet_RollbackOnDemand(t *testing.T) {
+	api, client, domainID := newFakeDomain(t, Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300})
+	defer api.Close()
+	before := fakeContents(api, domainID)
+
+	// A record deleted and recreated by the rollback gets a new ID, which
+	// the earlier changes to it are undone against.
+	cs := client.NewChangeSet(domainID)
+	if err := cs.Update("1", map[string]interface{}{"value": "5.5.5.5", "ttl": 60}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := cs.Delete("1"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if fakeContents(api, domainID) != "" {
+		t.Fatalf("changes not made: %s", fakeContents(api, domainID))
+	}
+
+	if err := cs.Rollback(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if after := fakeContents(api, domainID); after != before {
+		t.Fatalf("changes not rolled back:\n%s\n%s", after, before)
+	}
+	for _, r := range fakeRecords(api, domainID) {
+		if r.TTL != 300 {
+			t.Fatalf("ttl not restored: %#v", r)
+		}
+	}
+
+	// Committed changes are kept.
+	if _, err := cs.Create(map[string]interface{}{"name": "new", "type": "A", "value": "3.3.3.3"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cs.Commit()
+	if err := cs.Rollback(); err != nil || !strings.Contains(fakeContents(api, domainID), "new A 3.3.3.3") {
+		t.Fatalf("committed change rolled back: %v %s", err, fakeContents(api, domainID))
+	}
+}
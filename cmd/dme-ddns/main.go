@@ -0,0 +1,68 @@
+// Command dme-ddns keeps a DNS Made Easy dynamic DNS record pointing at the
+// public IP address of the host.
+//
+// The record password is read from the environment, for example:
+//
+//	% export DME_DDNS_USERNAME=myuser DME_DDNS_PASSWORD=secret
+//	% dme-ddns -record 10039429 -state /var/lib/dme-ddns/state.json
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/soniah/dnsmadeeasy/ddns"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	recordID := flag.String("record", os.Getenv("DME_RECORDID"), "ID of the dynamic DNS record")
+	iface := flag.String("interface", "", "detect the address of this interface rather than using an echo service")
+	ipv6 := flag.Bool("ipv6", false, "detect an IPv6 address on the interface")
+	echoURL := flag.String("echo-url", ddns.DefaultEchoURL, "URL of a service that returns the caller's address")
+	stateFile := flag.String("state", "", "file to store the last address sent in")
+	interval := flag.Duration("interval", ddns.DefaultInterval, "time between checks")
+	maxBackoff := flag.Duration("max-backoff", ddns.DefaultMaxBackoff, "longest wait between retries after a failure")
+	once := flag.Bool("once", false, "check once and exit")
+	flag.Parse()
+
+	username := os.Getenv("DME_DDNS_USERNAME")
+	password := os.Getenv("DME_DDNS_PASSWORD")
+	if len(*recordID) == 0 || len(username) == 0 || len(password) == 0 {
+		log.Fatalf("-record, DME_DDNS_USERNAME and DME_DDNS_PASSWORD must be set\n")
+	}
+
+	var detector ddns.Detector = &ddns.HTTPDetector{URL: *echoURL}
+	if len(*iface) != 0 {
+		detector = &ddns.InterfaceDetector{Name: *iface, IPv6: *ipv6}
+	}
+
+	updater := &ddns.Updater{
+		Username:   username,
+		Password:   password,
+		RecordID:   *recordID,
+		Detector:   detector,
+		StateFile:  *stateFile,
+		Interval:   *interval,
+		MaxBackoff: *maxBackoff,
+		Logger:     log.New(os.Stderr, "dme-ddns: ", log.LstdFlags),
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if *once {
+		changed, err := updater.RunOnce(ctx)
+		if err != nil {
+			log.Fatalf("err: %v", err)
+		}
+		log.Printf("Changed: %v", changed)
+		return
+	}
+
+	if err := updater.Run(ctx); err != nil && err != context.Canceled {
+		log.Fatalf("err: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/backup"
+	"os"
+	"path/filepath"
+)
+
+func runBackup(c *dme.Client, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	dir := fs.String("dir", ".", "directory to write the archive to")
+	var domains stringList
+	fs.Var(&domains, "domain", "back up only this domain (may be repeated)")
+	fs.Parse(args)
+
+	archive, err := backup.Dump(c, domains...)
+	if err != nil {
+		return err
+	}
+
+	// The archive is written under a temporary name so that a failed
+	// backup does not leave a partial archive behind.
+	path := filepath.Join(*dir, archive.Filename())
+	f, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %d domains to %s\n", len(archive.Domains), path)
+	return nil
+}
+
+func runRestore(c *dme.Client, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	var opts backup.Options
+	var domains stringList
+	fs.Var(&domains, "domain", "restore only this domain (may be repeated)")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "print the changes without making them")
+	fs.BoolVar(&opts.Prune, "prune", false, "delete records that are not in the archive")
+	fs.BoolVar(&opts.SkipAssignments, "skip-assignments", false,
+		"leave out SOA, vanity, template, folder and ACL assignments, as when restoring into another account")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: dmectl restore [flags] <archive>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	opts.Domains = domains
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	archive, err := backup.Read(f)
+	if err != nil {
+		return err
+	}
+
+	results, err := backup.Restore(c, archive, opts)
+	if werr := backup.WriteReport(os.Stdout, results); werr != nil && err == nil {
+		err = werr
+	}
+	if opts.DryRun {
+		fmt.Println("Dry run: no changes were made")
+	}
+	return err
+}
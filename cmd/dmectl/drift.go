@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/drift"
+	"github.com/soniah/dnsmadeeasy/zonedoc"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+func runDrift(c *dme.Client, args []string) error {
+	fs := flag.NewFlagSet("drift", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text, json or junit")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: dmectl drift [flags] <zone document>...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var write func(*drift.Report, io.Writer) error
+	switch *format {
+	case "text":
+		write = (*drift.Report).WriteText
+	case "json":
+		write = (*drift.Report).WriteJSON
+	case "junit":
+		write = (*drift.Report).WriteJUnit
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	var docs []*zonedoc.Document
+	for _, path := range fs.Args() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		doc, err := zonedoc.Decode(data)
+		if err != nil {
+			return fmt.Errorf("%s:\n%s", path, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	report, err := drift.Check(c, docs...)
+	if err != nil {
+		return err
+	}
+	if err := write(report, os.Stdout); err != nil {
+		return err
+	}
+
+	// Drift fails the command, so that CI jobs fail on it.
+	if report.Drifted() {
+		os.Exit(1)
+	}
+	return nil
+}
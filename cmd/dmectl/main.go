@@ -0,0 +1,79 @@
+// Command dmectl manages a DNS Made Easy account from the command line.
+//
+// The API keys are read from the environment, for example:
+//
+//	% export DME_AKEY=apikey DME_SKEY=secretkey
+//	% dmectl backup -dir /var/backups/dme
+//	% dmectl restore -dry-run -domain example.com dme-backup-20150102T150405Z.tar.gz
+//	% dmectl drift -format junit zones/*.yaml > drift.xml
+//
+// DME_URL may be set to use another API, such as the sandbox.
+package main
+
+import (
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"log"
+	"os"
+)
+
+type command struct {
+	run   func(c *dme.Client, args []string) error
+	usage string
+}
+
+var commands = map[string]command{
+	"backup":  {runBackup, "back up the domains of the account to an archive"},
+	"restore": {runRestore, "restore domains from an archive"},
+	"drift":   {runDrift, "report how live zones differ from zone documents"},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: dmectl <command> [flags]\n\ncommands:\n")
+	for _, name := range []string{"backup", "restore", "drift"} {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dmectl: ")
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+
+	akey := os.Getenv("DME_AKEY")
+	skey := os.Getenv("DME_SKEY")
+	if len(akey) == 0 || len(skey) == 0 {
+		log.Fatalf("DME_AKEY and DME_SKEY must be set\n")
+	}
+	client, err := dme.NewClient(akey, skey)
+	if err != nil {
+		log.Fatalf("err: %v", err)
+	}
+	if url := os.Getenv("DME_URL"); len(url) != 0 {
+		client.URL = url
+	}
+	client.Limiter = dme.NewRateLimiter(dme.DefaultRateLimit, dme.DefaultRatePeriod)
+
+	if err := cmd.run(client, os.Args[2:]); err != nil {
+		log.Fatalf("err: %v", err)
+	}
+}
+
+// stringList is a flag that may be given more than once.
+type stringList []string
+
+func (l *stringList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,161 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ContactListResponse is the response from a GET of all contact lists.
+type ContactListResponse struct {
+	Data []ContactList `json:"data"`
+}
+
+// ContactList is a named set of email addresses and groups that are
+// notified by monitors.
+type ContactList struct {
+	ID     int64    `json:"id,omitempty"`
+	Name   string   `json:"name"`
+	Emails []string `json:"emails"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// StringID returns the contact list id as a string.
+func (cl *ContactList) StringID() string {
+	return strconv.FormatInt(cl.ID, 10)
+}
+
+func contactListEndpoint(listID string) string {
+	if listID == "" {
+		return "/contactList"
+	}
+	return fmt.Sprintf("/contactList/%s", listID)
+}
+
+// ListContactLists gets all the contact lists of the account.
+func (c *Client) ListContactLists() ([]ContactList, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", contactListEndpoint(""), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving contact lists: %s", err)
+	}
+
+	listResp := ContactListResponse{}
+	err = decodeBody(resp, &listResp)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding contact list response: %s", err)
+	}
+	return listResp.Data, nil
+}
+
+// ReadContactList gets a contact list by the ID specified.
+func (c *Client) ReadContactList(listID string) (*ContactList, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", contactListEndpoint(listID), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving contact list: %s", err)
+	}
+
+	list := new(ContactList)
+	err = decodeBody(resp, list)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing contact list response: %s", err)
+	}
+	return list, nil
+}
+
+// ContactListByName finds a contact list by its name. Names are compared
+// exactly.
+func (c *Client) ContactListByName(name string) (*ContactList, error) {
+	lists, err := c.ListContactLists()
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.Name == name {
+			result := list // not pointer, so data copied
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find contact list %s", name)
+}
+
+// CreateContactList creates a contact list and returns its ID.
+func (c *Client) CreateContactList(cl *ContactList) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(cl); err != nil {
+		return "", err
+	}
+
+	req, err := c.NewRequest("POST", contactListEndpoint(""), buf, "")
+	if err != nil {
+		return "", fmt.Errorf("Error from NewRequest: %s", err)
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return "", fmt.Errorf("Error creating contact list: %s", err)
+	}
+
+	list := new(ContactList)
+	err = decodeBody(resp, list)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing contact list response: %s", err)
+	}
+
+	// The request was successful
+	return list.StringID(), nil
+}
+
+// UpdateContactList replaces the name and members of the contact list
+// specified and returns an error if it fails.
+func (c *Client) UpdateContactList(listID string, cl *ContactList) error {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(cl); err != nil {
+		return err
+	}
+
+	req, err := c.NewRequest("PUT", contactListEndpoint(listID), buf, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = checkResp(c.do(req))
+	if err != nil {
+		return fmt.Errorf("Error updating contact list: %s", err)
+	}
+
+	// The request was successful
+	return nil
+}
+
+// DeleteContactList destroys the contact list specified and returns an
+// error if it fails.
+func (c *Client) DeleteContactList(listID string) error {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("DELETE", contactListEndpoint(listID), body, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = checkResp(c.do(req))
+	if err != nil {
+		return fmt.Errorf("Error deleting contact list %s: %s", listID, err)
+	}
+
+	// The request was successful
+	return nil
+}
@@ -0,0 +1,78 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_ListContactListsGood(c *C) {
+	testServer.Response(200, nil, contactListsRead)
+	lists, err := s.client.ListContactLists()
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/contactList")
+	c.Assert(lists, HasLen, 2)
+	c.Assert(lists[1].Emails, DeepEquals, []string{"noc@example.com", "oncall@example.com"})
+}
+
+func (s *S) Test_CreateContactListGood(c *C) {
+	testServer.Response(201, nil, `{"id":3392,"name":"ops","emails":["ops@example.com"]}`)
+	id, err := s.client.CreateContactList(&ContactList{
+		Name:   "ops",
+		Emails: []string{"ops@example.com"},
+	})
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(id, Equals, "3392")
+}
+
+func (s *S) Test_DeleteContactListBad(c *C) {
+	testServer.Response(404, nil, "")
+	err := s.client.DeleteContactList("1")
+	_ = testServer.WaitRequest()
+	c.Assert(err, NotNil)
+}
+
+func (s *S) Test_ContactListByNameBad(c *C) {
+	testServer.Response(200, nil, contactListsRead)
+	list, err := s.client.ContactListByName("nobody")
+	_ = testServer.WaitRequest()
+	c.Assert(list, IsNil)
+	c.Assert(err, ErrorMatches, "Unable to find contact list nobody")
+}
+
+func (s *S) Test_UpdateMonitorContactListName(c *C) {
+	testServer.Response(200, nil, contactListsRead)
+	testServer.Response(200, nil, "")
+	m := &Monitor{Monitor: true, ContactListName: "noc"}
+	err := s.client.UpdateMonitor("10039429", m)
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent["contactListId"], Equals, float64(3391))
+	c.Assert(m.ContactListID, Equals, int64(0))
+}
+
+var contactListsRead = `{
+  "data":[
+    {
+      "id":3390,
+      "name":"Default",
+      "emails":["admin@example.com"]
+    },
+    {
+      "id":3391,
+      "name":"noc",
+      "emails":["noc@example.com","oncall@example.com"],
+      "groups":["operations"]
+    }
+  ],
+  "page":0,
+  "totalPages":1,
+  "totalRecords":2
+}`
@@ -0,0 +1,204 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TemplateSource is the Source of a record that comes from the domain's
+// template rather than from the domain itself.
+const TemplateSource = 0
+
+// ConflictAction is what CopyZone does with a record whose name and type
+// are already in use in the destination domain.
+type ConflictAction int
+
+const (
+	// ConflictSkip leaves the destination's records alone and does not
+	// copy the record.
+	ConflictSkip ConflictAction = iota
+
+	// ConflictOverwrite deletes the destination's records with the name
+	// and type, and copies the source's records in their place.
+	ConflictOverwrite
+)
+
+// CopyOptions configure CopyZone.
+type CopyOptions struct {
+	// RewriteOrigin replaces the name of the source domain with the name
+	// of the destination domain wherever it appears in record names and
+	// values, so that mail.example.com becomes mail.example.net.
+	RewriteOrigin bool
+
+	OnConflict ConflictAction
+}
+
+// CopyReport reports what CopyZone did.
+type CopyReport struct {
+	// Created are the records created in the destination, with their IDs.
+	Created []Record
+
+	// Deleted are the destination records deleted to make way for
+	// source records.
+	Deleted []Record
+
+	// Skipped are the source records not copied because of a conflict.
+	Skipped []Record
+
+	// Unchanged are the source records the destination already held.
+	Unchanged []Record
+
+	// FromTemplate are the source records not copied because they come
+	// from the source's template rather than from the domain itself.
+	FromTemplate []Record
+}
+
+// CopyZone copies the records of the source domain to the destination
+// domain. Records already in the destination are left as they are, and
+// records whose name and type are in use are handled as opts.OnConflict
+// says. The copies are made with one bulk create; if it fails, the
+// destination records deleted to make way for them are put back.
+func (c *Client) CopyZone(srcDomainID, dstDomainID string, opts CopyOptions) (*CopyReport, error) {
+	src, err := c.ReadDomain(srcDomainID)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := c.ReadDomain(dstDomainID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := c.ListRecords(srcDomainID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := c.ListRecords(dstDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The records are grouped by name and type, so that a set of records
+	// such as a round robin is treated as a whole.
+	type key struct{ name, rrtype string }
+	var order []key
+	groups := map[key][]Record{}
+	report := &CopyReport{}
+	for _, r := range records {
+		if r.Source == TemplateSource {
+			report.FromTemplate = append(report.FromTemplate, r)
+			continue
+		}
+		if opts.RewriteOrigin {
+			r.Name = rewriteOrigin(r.Name, src.Name, dst.Name)
+			r.Value = rewriteOrigin(r.Value, src.Name, dst.Name)
+		}
+		k := key{normalizeName(r.Name), strings.ToUpper(r.Type)}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+	inUse := map[key][]Record{}
+	for _, r := range existing {
+		k := key{normalizeName(r.Name), strings.ToUpper(r.Type)}
+		inUse[k] = append(inUse[k], r)
+	}
+
+	var create, remove []Record
+	for _, k := range order {
+		group, current := groups[k], inUse[k]
+		if sameRecordSet(group, current) {
+			report.Unchanged = append(report.Unchanged, group...)
+			continue
+		}
+		if len(current) > 0 && opts.OnConflict == ConflictSkip {
+			report.Skipped = append(report.Skipped, group...)
+			continue
+		}
+		remove = append(remove, current...)
+		create = append(create, group...)
+	}
+
+	cs := c.NewChangeSet(dstDomainID)
+	fail := func(err error) (*CopyReport, error) {
+		if rerr := cs.Rollback(); rerr != nil {
+			return report, fmt.Errorf("%s; rollback failed: %s", err, rerr)
+		}
+		report.Deleted = nil
+		return report, err
+	}
+	for i := range remove {
+		if err := cs.deleteRecord(&remove[i]); err != nil {
+			return fail(err)
+		}
+		report.Deleted = append(report.Deleted, remove[i])
+	}
+	if len(create) > 0 {
+		created, err := c.CreateRecords(dstDomainID, create)
+		if err != nil {
+			return fail(err)
+		}
+		report.Created = created
+	}
+	cs.Commit()
+	return report, nil
+}
+
+// sameRecordSet reports whether two sets of records hold the same
+// values, in any order.
+func sameRecordSet(a, b []Record) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for i := range a {
+		found := false
+		for j := range b {
+			if !matched[j] && SameContent(&a[i], &b[j]) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteOrigin replaces the whole name from with to in s, wherever it
+// appears as a domain name or the end of one. Case is ignored, so
+// MAIL.Example.COM. becomes MAIL.example.net.
+func rewriteOrigin(s, from, to string) string {
+	from = strings.ToLower(strings.TrimSuffix(from, "."))
+	to = strings.TrimSuffix(to, ".")
+	if from == "" {
+		return s
+	}
+
+	lower := strings.ToLower(s)
+	var b strings.Builder
+	last := 0
+	for i := 0; i+len(from) <= len(s); {
+		j := strings.Index(lower[i:], from)
+		if j < 0 {
+			break
+		}
+		start, end := i+j, i+j+len(from)
+		if (start == 0 || isLabelEnd(s[start-1])) && (end == len(s) || isLabelEnd(s[end])) {
+			b.WriteString(s[last:start])
+			b.WriteString(to)
+			last = end
+			i = end
+		} else {
+			i = start + 1
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// isLabelEnd reports whether b ends a label of a domain name.
+func isLabelEnd(b byte) bool {
+	return !(b == '-' || b == '_' ||
+		'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9')
+}
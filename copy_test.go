@@ -0,0 +1,121 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_CopyZone(c *C) {
+	testServer.Response(200, nil, `{"id":870073,"name":"example.com"}`)
+	testServer.Response(200, nil, `{"id":870074,"name":"example.net"}`)
+	testServer.Response(200, nil, copySource)
+	testServer.Response(200, nil, copyDestination)
+	testServer.Response(200, nil, "")
+	testServer.Response(201, nil, `[{"id":1,"name":"","type":"MX","value":"mail.example.net."},
+		{"id":2,"name":"www","type":"A","value":"1.1.1.1"}]`)
+
+	report, err := s.client.CopyZone("870073", "870074",
+		CopyOptions{RewriteOrigin: true, OnConflict: ConflictOverwrite})
+	reqs := testServer.WaitRequests(6)
+	c.Assert(err, IsNil)
+	c.Assert(reqs[4].Method, Equals, "DELETE")
+	c.Assert(reqs[4].URL.Path, Equals, "/dns/managed/870074/records/20000002/")
+	c.Assert(reqs[5].Method, Equals, "POST")
+	c.Assert(reqs[5].URL.Path, Equals, "/dns/managed/870074/records/createMulti")
+
+	body, _ := ioutil.ReadAll(reqs[5].Body)
+	sent := []Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent, HasLen, 2)
+	c.Assert(sent[0].Value, Equals, "mail.example.net.")
+	c.Assert(sent[1].Name, Equals, "www")
+
+	c.Assert(report.Created, HasLen, 2)
+	c.Assert(report.Deleted, HasLen, 1)
+	c.Assert(report.Deleted[0].Value, Equals, "9.9.9.9")
+	c.Assert(report.Unchanged, HasLen, 1)
+	c.Assert(report.Unchanged[0].Value, Equals, "v=spf1 include:example.net -all")
+	c.Assert(report.Skipped, HasLen, 0)
+
+	// Records from the source's template are not copied.
+	c.Assert(report.FromTemplate, HasLen, 1)
+	c.Assert(report.FromTemplate[0].Name, Equals, "ftp")
+}
+
+func (s *S) Test_CopyZoneRollsBack(c *C) {
+	testServer.Response(200, nil, `{"id":870073,"name":"example.com"}`)
+	testServer.Response(200, nil, `{"id":870074,"name":"example.net"}`)
+	testServer.Response(200, nil, copySource)
+	testServer.Response(200, nil, copyDestination)
+	testServer.Response(200, nil, "")
+	testServer.Response(400, nil, `{"error":["Bulk create failed"]}`)
+	testServer.Response(201, nil, `{"id":20000003,"name":"www","type":"A","value":"9.9.9.9"}`)
+
+	report, err := s.client.CopyZone("870073", "870074",
+		CopyOptions{RewriteOrigin: true, OnConflict: ConflictOverwrite})
+	reqs := testServer.WaitRequests(7)
+	c.Assert(err, ErrorMatches, "Error creating records: .*400.*")
+	c.Assert(reqs[4].Method, Equals, "DELETE")
+	c.Assert(reqs[5].URL.Path, Equals, "/dns/managed/870074/records/createMulti")
+
+	// The record deleted to make way for the copies is put back.
+	c.Assert(reqs[6].Method, Equals, "POST")
+	c.Assert(reqs[6].URL.Path, Equals, "/dns/managed/870074/records/")
+	body, _ := ioutil.ReadAll(reqs[6].Body)
+	sent := Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent.Name, Equals, "www")
+	c.Assert(sent.Value, Equals, "9.9.9.9")
+	c.Assert(report.Deleted, HasLen, 0)
+}
+
+func (s *S) Test_CopyZoneSkipConflicts(c *C) {
+	testServer.Response(200, nil, `{"id":870073,"name":"example.com"}`)
+	testServer.Response(200, nil, `{"id":870074,"name":"example.net"}`)
+	testServer.Response(200, nil, copySource)
+	testServer.Response(200, nil, copyDestination)
+	testServer.Response(201, nil, `[{"id":1,"name":"","type":"MX","value":"mail.example.com."}]`)
+
+	report, err := s.client.CopyZone("870073", "870074", CopyOptions{})
+	reqs := testServer.WaitRequests(5)
+	c.Assert(err, IsNil)
+	c.Assert(reqs[4].URL.Path, Equals, "/dns/managed/870074/records/createMulti")
+
+	// Without rewriting, the SPF record differs and conflicts.
+	c.Assert(report.Created, HasLen, 1)
+	c.Assert(report.Skipped, HasLen, 2)
+	c.Assert(report.Deleted, HasLen, 0)
+}
+
+func (s *S) Test_RewriteOrigin(c *C) {
+	for _, t := range []struct{ in, out string }{
+		{"example.com", "example.net"},
+		{"mail.Example.COM.", "mail.example.net."},
+		{"v=spf1 include:example.com include:_spf.example.com -all",
+			"v=spf1 include:example.net include:_spf.example.net -all"},
+		{"myexample.com", "myexample.com"},
+		{"example.community", "example.community"},
+		{"www", "www"},
+	} {
+		c.Assert(rewriteOrigin(t.in, "example.com", "example.net"), Equals, t.out)
+	}
+}
+
+var copySource = `{
+  "data":[
+    {"id":10000001,"name":"","type":"MX","value":"mail.example.com.","mxLevel":10,"ttl":86400,"gtdLocation":"DEFAULT","source":1},
+    {"id":10000002,"name":"www","type":"A","value":"1.1.1.1","ttl":300,"gtdLocation":"DEFAULT","source":1},
+    {"id":10000003,"name":"","type":"TXT","value":"v=spf1 include:example.com -all","ttl":300,"gtdLocation":"DEFAULT","source":1},
+    {"id":10000004,"name":"ftp","type":"CNAME","value":"www","ttl":300,"gtdLocation":"DEFAULT","source":0}
+  ],
+  "totalPages":1
+}`
+
+var copyDestination = `{
+  "data":[
+    {"id":20000001,"name":"","type":"TXT","value":"v=spf1 include:example.net -all","ttl":300,"gtdLocation":"DEFAULT","source":1},
+    {"id":20000002,"name":"www","type":"A","value":"9.9.9.9","ttl":300,"gtdLocation":"DEFAULT","source":1}
+  ],
+  "totalPages":1
+}`
@@ -0,0 +1,241 @@
+// Package ddns keeps a DNS Made Easy dynamic DNS record pointing at the
+// public IP address of the host.
+//
+// An Updater detects the address with a Detector, and sends it to the
+// dynamic DNS endpoint only when it differs from the last address sent.
+// The last address is persisted, so restarts do not cause updates.
+package ddns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the dynamic DNS update endpoint.
+const DefaultURL = "https://cp.dnsmadeeasy.com/servlet/updateip"
+
+// Defaults used by Run when the Updater leaves them unset.
+const (
+	DefaultInterval   = 5 * time.Minute
+	DefaultMinBackoff = 30 * time.Second
+	DefaultMaxBackoff = 30 * time.Minute
+)
+
+// State is what the Updater persists between runs.
+type State struct {
+	IP      string    `json:"ip"`
+	Updated time.Time `json:"updated"`
+}
+
+// Updater keeps one dynamic DNS record up to date.
+type Updater struct {
+	// URL of the update endpoint. DefaultURL will be used if not
+	// provided.
+	URL string
+
+	// Username is the account user name, and Password the dynamic DNS
+	// password set on the record.
+	Username string
+	Password string
+
+	// RecordID is the ID of the record to update.
+	RecordID string
+
+	Detector Detector
+
+	// StateFile is where the last address sent is stored. If empty, it
+	// is only kept in memory.
+	StateFile string
+
+	// Interval between checks, and the bounds of the backoff used after
+	// a failure.
+	Interval   time.Duration
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// HTTP is the client to use. Default will be used if not provided.
+	HTTP *http.Client
+
+	// Logger receives a line for each update and failure. Nothing is
+	// logged if not provided.
+	Logger *log.Logger
+
+	state *State
+}
+
+// Update sends ip to the update endpoint, regardless of the last address
+// sent.
+func (u *Updater) Update(ctx context.Context, ip net.IP) error {
+	endpoint := u.URL
+	if endpoint == "" {
+		endpoint = DefaultURL
+	}
+	params := url.Values{}
+	params.Set("username", u.Username)
+	params.Set("password", u.Password)
+	params.Set("id", u.RecordID)
+	params.Set("ip", ip.String())
+
+	req, err := http.NewRequest("GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("Error creating request: %s", redact(err, endpoint))
+	}
+	client := u.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("Error updating record %s: %s", u.RecordID, redact(err, endpoint))
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	result := strings.TrimSpace(string(body))
+	if resp.StatusCode/100 != 2 || result != "success" {
+		return fmt.Errorf("Error updating record %s: %s %s", u.RecordID,
+			resp.Status, result)
+	}
+	return nil
+}
+
+// redact replaces the URL in a request error, which holds the password in
+// its query, with the endpoint.
+func redact(err error, endpoint string) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{Op: urlErr.Op, URL: endpoint, Err: urlErr.Err}
+	}
+	return err
+}
+
+// RunOnce detects the address and updates the record if it has changed
+// since the last update. It reports whether an update was sent.
+func (u *Updater) RunOnce(ctx context.Context) (bool, error) {
+	if u.state == nil {
+		state, err := loadState(u.StateFile)
+		if err != nil {
+			return false, err
+		}
+		u.state = state
+	}
+
+	ip, err := u.Detector.Detect(ctx)
+	if err != nil {
+		return false, err
+	}
+	if ip.String() == u.state.IP {
+		return false, nil
+	}
+
+	if err := u.Update(ctx, ip); err != nil {
+		return false, err
+	}
+	u.logf("Updated record %s from %q to %s", u.RecordID, u.state.IP, ip)
+
+	u.state = &State{IP: ip.String(), Updated: time.Now().UTC()}
+	if err := saveState(u.StateFile, u.state); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// Run calls RunOnce every Interval until ctx is done. After a failure it
+// retries with exponential backoff, between MinBackoff and MaxBackoff.
+func (u *Updater) Run(ctx context.Context) error {
+	interval := orDefault(u.Interval, DefaultInterval)
+	minBackoff := orDefault(u.MinBackoff, DefaultMinBackoff)
+	maxBackoff := orDefault(u.MaxBackoff, DefaultMaxBackoff)
+
+	backoff := minBackoff
+	for {
+		wait := interval
+		if _, err := u.RunOnce(ctx); err != nil {
+			u.logf("Error: %s, retrying in %s", err, backoff)
+			wait = backoff
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		} else {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (u *Updater) logf(format string, v ...interface{}) {
+	if u.Logger != nil {
+		u.Logger.Printf(format, v...)
+	}
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// loadState reads the state file. A missing file is an empty state.
+func loadState(path string) (*State, error) {
+	state := new(State)
+	if path == "" {
+		return state, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Error reading state: %s", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("Error parsing state %s: %s", path, err)
+	}
+	return state, nil
+}
+
+// saveState writes the state file, replacing it atomically.
+func saveState(path string, state *State) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("Error writing state: %s", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("Error writing state: %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("Error writing state: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Error writing state: %s", err)
+	}
+	return nil
+}
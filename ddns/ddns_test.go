@@ -0,0 +1,174 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type staticDetector struct {
+	mu sync.Mutex
+	ip string
+}
+
+func (d *staticDetector) Detect(ctx context.Context) (net.IP, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return net.ParseIP(d.ip), nil
+}
+
+func (d *staticDetector) set(ip string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ip = ip
+}
+
+type updateServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	queries []string
+	fail    bool
+}
+
+func newUpdateServer() *updateServer {
+	s := &updateServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.queries = append(s.queries, r.URL.RawQuery)
+		if s.fail {
+			fmt.Fprint(w, "error-auth")
+			return
+		}
+		fmt.Fprint(w, "success")
+	}))
+	return s
+}
+
+func (s *updateServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queries)
+}
+
+func TestRunOnceUpdatesOnlyOnChange(t *testing.T) {
+	server := newUpdateServer()
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "ddns")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	detector := &staticDetector{ip: "1.1.1.1"}
+	u := &Updater{
+		URL:       server.URL,
+		Username:  "user",
+		Password:  "secret",
+		RecordID:  "10039429",
+		Detector:  detector,
+		StateFile: filepath.Join(dir, "state.json"),
+	}
+
+	for i, want := range []bool{true, false} {
+		changed, err := u.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+		if changed != want {
+			t.Fatalf("run %d: changed %v, want %v", i, changed, want)
+		}
+	}
+	if q := server.queries[0]; q != "id=10039429&ip=1.1.1.1&password=secret&username=user" {
+		t.Fatalf("bad query: %s", q)
+	}
+
+	// A new updater reads the persisted state and does not update.
+	u2 := &Updater{URL: server.URL, Detector: detector, StateFile: u.StateFile}
+	if changed, err := u2.RunOnce(context.Background()); err != nil || changed {
+		t.Fatalf("restart: changed %v, err %v", changed, err)
+	}
+
+	detector.set("2.2.2.2")
+	if changed, err := u2.RunOnce(context.Background()); err != nil || !changed {
+		t.Fatalf("new address: changed %v, err %v", changed, err)
+	}
+	if server.count() != 2 {
+		t.Fatalf("bad number of updates: %d", server.count())
+	}
+}
+
+func TestRunOnceFailureKeepsState(t *testing.T) {
+	server := newUpdateServer()
+	defer server.Close()
+	server.fail = true
+
+	u := &Updater{URL: server.URL, RecordID: "1", Detector: &staticDetector{ip: "1.1.1.1"}}
+	if _, err := u.RunOnce(context.Background()); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if u.state.IP != "" {
+		t.Fatalf("state changed after failure: %v", u.state)
+	}
+}
+
+func TestUpdateErrorHidesPassword(t *testing.T) {
+	server := newUpdateServer()
+	server.Close()
+
+	u := &Updater{URL: server.URL, Username: "me", Password: "hunter2", RecordID: "1"}
+	err := u.Update(context.Background(), net.ParseIP("1.1.1.1"))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), server.URL) {
+		t.Fatalf("bad error: %v", err)
+	}
+}
+
+func TestRunRetriesWithBackoff(t *testing.T) {
+	server := newUpdateServer()
+	defer server.Close()
+	server.fail = true
+
+	u := &Updater{
+		URL:        server.URL,
+		RecordID:   "1",
+		Detector:   &staticDetector{ip: "1.1.1.1"},
+		Interval:   time.Hour,
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := u.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("err: %v", err)
+	}
+	if server.count() < 3 {
+		t.Fatalf("expected retries, got %d attempts", server.count())
+	}
+}
+
+func TestHTTPDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "203.0.113.7\n")
+	}))
+	defer server.Close()
+
+	ip, err := (&HTTPDetector{URL: server.URL}).Detect(context.Background())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ip.String() != "203.0.113.7" {
+		t.Fatalf("bad ip: %v", ip)
+	}
+}
@@ -0,0 +1,89 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Detector finds the public IP address of the host.
+type Detector interface {
+	Detect(ctx context.Context) (net.IP, error)
+}
+
+// InterfaceDetector uses the first global unicast address of a network
+// interface. It suits hosts whose public address is assigned directly.
+type InterfaceDetector struct {
+	// Name is the name of the interface, for example eth0.
+	Name string
+
+	// IPv6 selects an IPv6 address rather than an IPv4 one.
+	IPv6 bool
+}
+
+// Detect returns the address of the interface.
+func (d *InterfaceDetector) Detect(ctx context.Context) (net.IP, error) {
+	iface, err := net.InterfaceByName(d.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding interface %s: %s", d.Name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("Error listing addresses of %s: %s", d.Name, err)
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || !ipnet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if (ipnet.IP.To4() == nil) == d.IPv6 {
+			return ipnet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("No global unicast address on %s", d.Name)
+}
+
+// HTTPDetector asks an echo service, which returns the address of the
+// client as the plain text body of its response.
+type HTTPDetector struct {
+	URL string
+
+	// HTTP is the client to use. Default will be used if not provided.
+	HTTP *http.Client
+}
+
+// DefaultEchoURL is an echo service that returns the caller's IPv4 address.
+const DefaultEchoURL = "https://api.ipify.org"
+
+// Detect returns the address reported by the echo service.
+func (d *HTTPDetector) Detect(ctx context.Context) (net.IP, error) {
+	client := d.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest("GET", d.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating request: %s", err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("Error querying %s: %s", d.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("Error querying %s: %s", d.URL, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("Invalid address from %s: %q", d.URL, body)
+	}
+	return ip, nil
+}
@@ -0,0 +1,169 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// DomainResponse is the response from a GET of all managed domains.
+type DomainResponse struct {
+	Data []Domain `json:"data"`
+}
+
+// NameServer is a nameserver that serves a domain.
+type NameServer struct {
+	Fqdn string `json:"fqdn"`
+	IPv4 string `json:"ipv4"`
+	IPv6 string `json:"ipv6"`
+}
+
+// Domain is used to represent a managed domain.
+type Domain struct {
+	ID          int64        `json:"id"`
+	Name        string       `json:"name"`
+	GtdEnabled  bool         `json:"gtdEnabled"`
+	NameServers []NameServer `json:"nameServers"`
+	Created     int64        `json:"created"`
+	Updated     int64        `json:"updated"`
+
+	// The custom SOA record, vanity nameservers, template, folder and
+	// transfer ACL assigned to the domain, or 0 for none.
+	SOAID         int64 `json:"soaId,omitempty"`
+	VanityID      int64 `json:"vanityId,omitempty"`
+	TemplateID    int64 `json:"templateId,omitempty"`
+	FolderID      int64 `json:"folderId,omitempty"`
+	TransferACLID int64 `json:"transferAclId,omitempty"`
+}
+
+// Assignments returns the fields of the domain that assign it an SOA
+// record, vanity nameservers, template, folder and transfer ACL, in the
+// form given to UpdateDomain. Unassigned fields are left out.
+func (d *Domain) Assignments() map[string]interface{} {
+	fields := map[string]interface{}{}
+	for name, id := range map[string]int64{
+		"soaId":         d.SOAID,
+		"vanityId":      d.VanityID,
+		"templateId":    d.TemplateID,
+		"folderId":      d.FolderID,
+		"transferAclId": d.TransferACLID,
+	} {
+		if id != 0 {
+			fields[name] = id
+		}
+	}
+	return fields
+}
+
+// StringID returns the domain id as a string.
+func (d *Domain) StringID() string {
+	return strconv.FormatInt(d.ID, 10)
+}
+
+func domainEndpoint(domainID string) string {
+	if domainID == "" {
+		return "/dns/managed/"
+	}
+	return fmt.Sprintf("/dns/managed/%s", domainID)
+}
+
+// ListDomains gets all the managed domains of the account.
+func (c *Client) ListDomains() ([]Domain, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", domainEndpoint(""), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving domains: %s", err)
+	}
+
+	domainResp := DomainResponse{}
+	err = decodeBody(resp, &domainResp)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding domain response: %s", err)
+	}
+	return domainResp.Data, nil
+}
+
+// ReadDomain gets a managed domain by the ID specified.
+func (c *Client) ReadDomain(domainID string) (*Domain, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", domainEndpoint(domainID), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving domain: %s", err)
+	}
+
+	domain := new(Domain)
+	err = decodeBody(resp, domain)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing domain response: %s", err)
+	}
+	return domain, nil
+}
+
+// CreateDomain creates a managed domain with the name given and returns
+// it.
+func (c *Client) CreateDomain(name string) (*Domain, error) {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(map[string]interface{}{"name": name}); err != nil {
+		return nil, err
+	}
+
+	req, err := c.NewRequest("POST", domainEndpoint(""), buf, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating domain: %s", err)
+	}
+
+	domain := new(Domain)
+	err = decodeBody(resp, domain)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing domain response: %s", err)
+	}
+	return domain, nil
+}
+
+// UpdateDomain updates the domain specified with the fields given and
+// returns an error if it fails. Fields not given are left unchanged.
+func (c *Client) UpdateDomain(domainID string, cr map[string]interface{}) error {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(cr); err != nil {
+		return err
+	}
+
+	req, err := c.NewRequest("PUT", domainEndpoint(domainID), buf, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = checkResp(c.do(req))
+	if err != nil {
+		return fmt.Errorf("Error updating domain: %s", err)
+	}
+
+	// The request was successful
+	return nil
+}
+
+// SetGTDEnabled turns Global Traffic Director on or off for the domain
+// specified.
+func (c *Client) SetGTDEnabled(domainID string, enabled bool) error {
+	return c.UpdateDomain(domainID, map[string]interface{}{
+		"gtdEnabled": enabled,
+	})
+}
@@ -0,0 +1,92 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_ListDomainsGood(c *C) {
+	testServer.Response(200, nil, domainsRead)
+	domains, err := s.client.ListDomains()
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/dns/managed/")
+	c.Assert(domains, HasLen, 2)
+	c.Assert(domains[0].Name, Equals, "example.com")
+	c.Assert(domains[0].StringID(), Equals, "870073")
+	c.Assert(domains[0].NameServers[0].Fqdn, Equals, "ns0.dnsmadeeasy.com")
+}
+
+func (s *S) Test_ReadDomainBad(c *C) {
+	testServer.Response(404, nil, "")
+	domain, err := s.client.ReadDomain("1")
+	_ = testServer.WaitRequest()
+	c.Assert(err, NotNil)
+	c.Assert(domain, IsNil)
+}
+
+func (s *S) Test_SetGTDEnabled(c *C) {
+	testServer.Response(200, nil, "")
+	err := s.client.SetGTDEnabled("870073", true)
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.Method, Equals, "PUT")
+	c.Assert(req.URL.Path, Equals, "/dns/managed/870073")
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent, DeepEquals, map[string]interface{}{"gtdEnabled": true})
+}
+
+func (s *S) Test_CreateDomain(c *C) {
+	testServer.Response(201, nil, `{"id":870075,"name":"example.org","gtdEnabled":false}`)
+	domain, err := s.client.CreateDomain("example.org")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.Method, Equals, "POST")
+	c.Assert(req.URL.Path, Equals, "/dns/managed/")
+	c.Assert(domain.StringID(), Equals, "870075")
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent, DeepEquals, map[string]interface{}{"name": "example.org"})
+}
+
+func (s *S) Test_DomainAssignments(c *C) {
+	domain := Domain{SOAID: 1, VanityID: 2, TransferACLID: 5}
+	c.Assert(domain.Assignments(), DeepEquals, map[string]interface{}{
+		"soaId":         int64(1),
+		"vanityId":      int64(2),
+		"transferAclId": int64(5),
+	})
+}
+
+var domainsRead = `{
+  "data":[
+    {
+      "id":870073,
+      "name":"example.com",
+      "gtdEnabled":false,
+      "nameServers":[
+        {"fqdn":"ns0.dnsmadeeasy.com","ipv4":"208.94.148.2","ipv6":"2600:1800:0::1"},
+        {"fqdn":"ns1.dnsmadeeasy.com","ipv4":"208.80.124.2","ipv6":"2600:1801:1::1"}
+      ],
+      "created":1417651200000,
+      "updated":1417737600000
+    },
+    {
+      "id":870074,
+      "name":"example.net",
+      "gtdEnabled":true,
+      "nameServers":[],
+      "created":1417651200000,
+      "updated":1417737600000
+    }
+  ],
+  "page":0,
+  "totalPages":1,
+  "totalRecords":2
+}`
@@ -0,0 +1,248 @@
+// Package drift compares zones described by desired-state documents with
+// the live zones of an account, and reports the records that are missing,
+// extra, or differ. It makes no changes.
+package drift
+
+import (
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/zonedoc"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kind is the kind of a Difference.
+type Kind string
+
+// Kinds of Difference.
+const (
+	// Missing is a desired record the live zone does not have.
+	Missing Kind = "missing"
+
+	// Extra is a live record that is not desired.
+	Extra Kind = "extra"
+
+	// Changed is a live record whose fields differ from the desired
+	// record.
+	Changed Kind = "changed"
+)
+
+// FieldDiff is a field that differs between a desired and a live record.
+// Values are formatted as in JSON.
+type FieldDiff struct {
+	Field string `json:"field"`
+	Want  string `json:"want"`
+	Have  string `json:"have"`
+}
+
+// Difference is a record that has drifted.
+type Difference struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Want is the desired record, and Have the live record. Want is nil
+	// for an Extra record, and Have for a Missing one.
+	Want *dme.Record `json:"want,omitempty"`
+	Have *dme.Record `json:"have,omitempty"`
+
+	// Fields are the fields that differ, for a Changed record.
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// DomainReport is the drift of one domain.
+type DomainReport struct {
+	Domain string `json:"domain"`
+
+	// Error is set if the domain could not be checked, as when it is not
+	// in the account.
+	Error string `json:"error,omitempty"`
+
+	// InSync are the desired records the live zone matches.
+	InSync []dme.Record `json:"-"`
+
+	Differences []Difference `json:"differences"`
+}
+
+// Drifted reports whether the domain differs from its desired state.
+func (d *DomainReport) Drifted() bool {
+	return d.Error != "" || len(d.Differences) > 0
+}
+
+// Report is the drift of every domain checked.
+type Report struct {
+	Domains []DomainReport `json:"domains"`
+}
+
+// Drifted reports whether any domain differs from its desired state.
+func (r *Report) Drifted() bool {
+	for i := range r.Domains {
+		if r.Domains[i].Drifted() {
+			return true
+		}
+	}
+	return false
+}
+
+// Check compares the live zone of each document's domain with the
+// document. Domains that cannot be found are reported, not returned as
+// errors; the error is for failures to talk to the API.
+func Check(c *dme.Client, docs ...*zonedoc.Document) (*Report, error) {
+	domains, err := c.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for i := range domains {
+		ids[strings.ToLower(domains[i].Name)] = domains[i].StringID()
+	}
+
+	report := &Report{}
+	for _, doc := range docs {
+		d := DomainReport{Domain: doc.Domain.Name}
+		id, ok := ids[strings.ToLower(doc.Domain.Name)]
+		if !ok {
+			d.Error = "domain not found in account"
+			report.Domains = append(report.Domains, d)
+			continue
+		}
+		live, err := c.ListRecords(id)
+		if err != nil {
+			return nil, fmt.Errorf("Error checking %s: %s", doc.Domain.Name, err)
+		}
+		d.InSync, d.Differences = Compare(doc.ToRecords(), live)
+		report.Domains = append(report.Domains, d)
+	}
+	return report, nil
+}
+
+// Compare compares desired records with live ones, returning the desired
+// records that are matched exactly and the differences. Records are paired
+// by name and type, and records of a set, such as a round robin, are
+// paired by value and then by GTD location where they can be.
+func Compare(desired, live []dme.Record) ([]dme.Record, []Difference) {
+	type key struct{ name, rrtype string }
+	keyOf := func(r *dme.Record) key {
+		fields := dme.ContentFields(r)
+		return key{fields["name"].(string), fields["type"].(string)}
+	}
+	var order []key
+	want := map[key][]dme.Record{}
+	have := map[key][]dme.Record{}
+	for _, r := range desired {
+		k := keyOf(&r)
+		if _, ok := want[k]; !ok {
+			order = append(order, k)
+		}
+		want[k] = append(want[k], r)
+	}
+	for _, r := range live {
+		k := keyOf(&r)
+		if _, ok := want[k]; !ok {
+			if _, ok := have[k]; !ok {
+				order = append(order, k)
+			}
+		}
+		have[k] = append(have[k], r)
+	}
+
+	var inSync []dme.Record
+	var diffs []Difference
+	for _, k := range order {
+		w, h := want[k], have[k]
+		usedW := make([]bool, len(w))
+		usedH := make([]bool, len(h))
+
+		// Pair the records that match exactly, then those that share a
+		// value, then those that share a location, then the rest in order.
+		matchers := []func(a, b *dme.Record) bool{
+			func(a, b *dme.Record) bool { return len(fieldDiffs(a, b)) == 0 },
+			func(a, b *dme.Record) bool { return a.Value == b.Value },
+			func(a, b *dme.Record) bool { return location(a) == location(b) },
+			func(a, b *dme.Record) bool { return true },
+		}
+		for _, match := range matchers {
+			for i := range w {
+				for j := range h {
+					if usedW[i] || usedH[j] || !match(&w[i], &h[j]) {
+						continue
+					}
+					usedW[i], usedH[j] = true, true
+					fields := fieldDiffs(&w[i], &h[j])
+					if len(fields) == 0 {
+						inSync = append(inSync, w[i])
+						continue
+					}
+					diffs = append(diffs, Difference{Kind: Changed, Name: w[i].Name, Type: w[i].Type,
+						Want: &w[i], Have: &h[j], Fields: fields})
+				}
+			}
+		}
+		for i := range w {
+			if !usedW[i] {
+				diffs = append(diffs, Difference{Kind: Missing, Name: w[i].Name, Type: w[i].Type, Want: &w[i]})
+			}
+		}
+		for j := range h {
+			if !usedH[j] {
+				diffs = append(diffs, Difference{Kind: Extra, Name: h[j].Name, Type: h[j].Type, Have: &h[j]})
+			}
+		}
+	}
+	return inSync, diffs
+}
+
+// ignoredFields are left out of comparisons. Documents do not hold the
+// passwords of dynamic DNS records.
+var ignoredFields = []string{"password"}
+
+// fieldDiffs returns the fields that differ between a desired and a live
+// record, sorted by name. A desired TTL of 0 leaves the TTL to the API's
+// default, so any live TTL matches it.
+func fieldDiffs(want, have *dme.Record) []FieldDiff {
+	w, h := dme.ContentFields(want), dme.ContentFields(have)
+	for _, name := range ignoredFields {
+		delete(w, name)
+		delete(h, name)
+	}
+	if want.TTL == 0 {
+		delete(w, "ttl")
+		delete(h, "ttl")
+	}
+	var names []string
+	for name := range w {
+		names = append(names, name)
+	}
+	for name := range h {
+		if _, ok := w[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []FieldDiff
+	for _, name := range names {
+		if !reflect.DeepEqual(w[name], h[name]) {
+			diffs = append(diffs, FieldDiff{Field: name, Want: format(w[name]), Have: format(h[name])})
+		}
+	}
+	return diffs
+}
+
+func location(r *dme.Record) string {
+	if r.GtdLocation == "" {
+		return string(dme.GTDDefault)
+	}
+	return r.GtdLocation
+}
+
+func format(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,183 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/zonedoc"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const desired = `apiVersion: dnsmadeeasy/v1
+kind: Zone
+domain:
+  name: example.com
+records:
+  - name: ""
+    type: MX
+    value: mail
+    ttl: 86400
+    mxLevel: 10
+  - name: www
+    type: A
+    value: 1.1.1.1
+    ttl: 300
+  - name: www
+    type: A
+    value: 2.2.2.2
+    ttl: 300
+  - name: ftp
+    type: CNAME
+    value: www
+    ttl: 300
+`
+
+const live = `{"data":[
+  {"id":1,"name":"","type":"MX","value":"mail","ttl":86400,"mxLevel":10,"gtdLocation":"DEFAULT","source":1},
+  {"id":2,"name":"www","type":"A","value":"1.1.1.1","ttl":600,"gtdLocation":"DEFAULT","source":1},
+  {"id":3,"name":"www","type":"A","value":"2.2.2.2","ttl":300,"gtdLocation":"DEFAULT","source":1},
+  {"id":4,"name":"old","type":"A","value":"3.3.3.3","ttl":300,"gtdLocation":"DEFAULT","source":1}
+],"totalPages":1}`
+
+func checkExample(t *testing.T) *Report {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns/managed/":
+			w.Write([]byte(`{"data":[{"id":870073,"name":"example.com"}]}`))
+		case "/dns/managed/870073/records/":
+			w.Write([]byte(live))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+	client, _ := dme.NewClient("akey", "skey")
+	client.URL = server.URL
+
+	doc, err := zonedoc.Decode([]byte(desired))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	missing := zonedoc.New("example.org", nil)
+	report, err := Check(client, doc, missing)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return report
+}
+
+func TestCheckText(t *testing.T) {
+	report := checkExample(t)
+	if !report.Drifted() {
+		t.Fatalf("drift not reported")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := report.WriteText(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := `example.com: 3 differences
+  changed  www A 1.1.1.1
+           ttl: want 300, have 600
+  missing  ftp CNAME www
+  extra    old A 3.3.3.3
+example.org: domain not found in account
+`
+	if buf.String() != expected {
+		t.Fatalf("bad report:\n%s", buf)
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := checkExample(t).WriteJSON(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var back Report
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	diffs := back.Domains[0].Differences
+	if len(diffs) != 3 || diffs[0].Kind != Changed || diffs[0].Have.RecordID != 2 ||
+		diffs[0].Fields[0] != (FieldDiff{"ttl", "300", "600"}) {
+		t.Fatalf("bad json report: %s", buf)
+	}
+}
+
+func TestCheckJUnit(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := checkExample(t).WriteJUnit(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("err: %v\n%s", err, buf)
+	}
+	if suites.Tests != 6 || suites.Failures != 4 || len(suites.Suites) != 2 {
+		t.Fatalf("bad junit report:\n%s", buf)
+	}
+	suite := suites.Suites[0]
+	if suite.Name != "example.com" || suite.Tests != 5 || suite.Failures != 3 {
+		t.Fatalf("bad suite:\n%s", buf)
+	}
+	if !strings.Contains(buf.String(), `<failure message="record is changed" type="changed">ttl: want 300, have 600</failure>`) {
+		t.Fatalf("bad failure:\n%s", buf)
+	}
+}
+
+func TestCompareInSync(t *testing.T) {
+	records := []dme.Record{
+		{Name: "@", Type: "A", Value: "1.1.1.1", TTL: 300},
+		{Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300, GtdLocation: "DEFAULT"},
+	}
+	live := []dme.Record{
+		{RecordID: 2, Name: "WWW", Type: "A", Value: "1.1.1.1", TTL: 300, GtdLocation: "DEFAULT"},
+		{RecordID: 1, Name: "", Type: "a", Value: "1.1.1.1", TTL: 300, Password: "secret"},
+	}
+	inSync, diffs := Compare(records, live)
+	if len(inSync) != 2 || len(diffs) != 0 {
+		t.Fatalf("bad comparison: %#v %#v", inSync, diffs)
+	}
+
+	// A record without a TTL matches any live TTL.
+	records = []dme.Record{{Name: "mail", Type: "A", Value: "2.2.2.2"}}
+	live = []dme.Record{{RecordID: 3, Name: "mail", Type: "A", Value: "2.2.2.2", TTL: 1800}}
+	inSync, diffs = Compare(records, live)
+	if len(inSync) != 1 || len(diffs) != 0 {
+		t.Fatalf("bad comparison: %#v %#v", inSync, diffs)
+	}
+}
+
+func TestWriteRecordWithoutTTL(t *testing.T) {
+	// A desired record without a TTL reports only the fields that differ,
+	// not the live TTL.
+	desired := []dme.Record{{Name: "mail", Type: "A", Value: "2.2.2.2"}}
+	live := []dme.Record{{RecordID: 3, Name: "mail", Type: "A", Value: "2.2.2.3", TTL: 1800}}
+	d := DomainReport{Domain: "example.com"}
+	d.InSync, d.Differences = Compare(desired, live)
+	report := &Report{Domains: []DomainReport{d}}
+
+	buf := bytes.NewBuffer(nil)
+	if err := report.WriteText(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := `example.com: 1 differences
+  changed  mail A 2.2.2.2
+           value: want "2.2.2.2", have "2.2.2.3"
+`
+	if buf.String() != expected {
+		t.Fatalf("bad report:\n%s", buf)
+	}
+
+	buf.Reset()
+	if err := report.WriteJUnit(buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<failure message="record is changed" type="changed">value: want &#34;2.2.2.2&#34;, have &#34;2.2.2.3&#34;</failure>`) {
+		t.Fatalf("bad failure:\n%s", buf)
+	}
+}
@@ -0,0 +1,142 @@
+package drift
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"io"
+	"strings"
+)
+
+// WriteText writes the report for people to read.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, d := range r.Domains {
+		var err error
+		switch {
+		case d.Error != "":
+			_, err = fmt.Fprintf(w, "%s: %s\n", d.Domain, d.Error)
+		case len(d.Differences) == 0:
+			_, err = fmt.Fprintf(w, "%s: in sync (%d records)\n", d.Domain, len(d.InSync))
+		default:
+			_, err = fmt.Fprintf(w, "%s: %d differences\n", d.Domain, len(d.Differences))
+		}
+		if err != nil {
+			return err
+		}
+		for _, diff := range d.Differences {
+			if _, err := fmt.Fprintf(w, "  %-8s %s\n", diff.Kind, diff.describe()); err != nil {
+				return err
+			}
+			for _, f := range diff.Fields {
+				if _, err := fmt.Fprintf(w, "           %s: want %s, have %s\n", f.Field, f.Want, f.Have); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// JUnit XML, as read by CI servers. Each domain is a test suite, and each
+// record a test case that fails if it has drifted.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, so that CI fails on drift.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suites := junitSuites{}
+	for _, d := range r.Domains {
+		suite := junitSuite{Name: d.Domain}
+		if d.Error != "" {
+			suite.Cases = append(suite.Cases, junitCase{Name: d.Domain, ClassName: d.Domain,
+				Failure: &junitFailure{Message: d.Error, Type: "error"}})
+		}
+		for i := range d.InSync {
+			suite.Cases = append(suite.Cases, junitCase{Name: describe(&d.InSync[i]), ClassName: d.Domain})
+		}
+		for _, diff := range d.Differences {
+			var text []string
+			for _, f := range diff.Fields {
+				text = append(text, fmt.Sprintf("%s: want %s, have %s", f.Field, f.Want, f.Have))
+			}
+			suite.Cases = append(suite.Cases, junitCase{Name: diff.describe(), ClassName: d.Domain,
+				Failure: &junitFailure{
+					Message: fmt.Sprintf("record is %s", diff.Kind),
+					Type:    string(diff.Kind),
+					Text:    strings.Join(text, "\n"),
+				}})
+		}
+		for _, c := range suite.Cases {
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// describe names the record that has drifted: the desired record, or the
+// live record if it is extra.
+func (d *Difference) describe() string {
+	if d.Want != nil {
+		return describe(d.Want)
+	}
+	return describe(d.Have)
+}
+
+func describe(r *dme.Record) string {
+	name := r.Name
+	if name == "" {
+		name = "@"
+	}
+	s := fmt.Sprintf("%s %s %s", name, r.Type, r.Value)
+	if r.GtdLocation != "" && r.GtdLocation != string(dme.GTDDefault) {
+		s += " (" + r.GtdLocation + ")"
+	}
+	return s
+}
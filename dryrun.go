@@ -0,0 +1,64 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// DryRunID is the ID of a record or domain "created" in dry-run mode.
+const DryRunID = "0"
+
+// PreparedRequest is a request built and signed in dry-run mode, exactly
+// as it would have been sent.
+type PreparedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+func (r *PreparedRequest) String() string {
+	if len(r.Body) == 0 {
+		return fmt.Sprintf("%s %s", r.Method, r.URL)
+	}
+	return fmt.Sprintf("%s %s %s", r.Method, r.URL, bytes.TrimSpace(r.Body))
+}
+
+// dryRun handles a mutating request in dry-run mode: it is given to
+// OnDryRun, or logged, and not sent. The response echoes the request body,
+// so a create returns what it would have created, with the ID DryRunID.
+func (c *Client) dryRun(req *http.Request) (*http.Response, error) {
+	prepared := &PreparedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		prepared.Body = body
+	}
+
+	if c.OnDryRun != nil {
+		c.OnDryRun(prepared)
+	} else {
+		log.Printf("dnsmadeeasy: dry run: %s", prepared)
+	}
+
+	body := prepared.Body
+	if len(bytes.TrimSpace(body)) == 0 {
+		body = []byte("{}")
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
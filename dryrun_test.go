@@ -0,0 +1,74 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClient_DryRun(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		w.Write([]byte(`{"data":[{"id":10039430,"name":"www","type":"A","value":"1.1.1.1","ttl":300}],"totalPages":1}`))
+	}))
+	defer server.Close()
+
+	client := makeClient(t)
+	client.URL = server.URL
+	client.DryRun = true
+	var previews []*PreparedRequest
+	client.OnDryRun = func(req *PreparedRequest) {
+		previews = append(previews, req)
+	}
+
+	id, err := client.CreateRecord("870073", map[string]interface{}{
+		"name": "test", "type": "A", "value": "1.1.1.2", "ttl": 300,
+	})
+	if err != nil || id != DryRunID {
+		t.Fatalf("bad create: %q, %v", id, err)
+	}
+	if _, err := client.UpdateRecord("870073", "10039430", map[string]interface{}{"value": "1.1.1.3"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := client.DeleteRecord("870073", "10039430"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Only the read made by the update reached the server.
+	if len(methods) != 1 || methods[0] != "GET" {
+		t.Fatalf("bad requests sent: %v", methods)
+	}
+	if len(previews) != 3 {
+		t.Fatalf("bad previews: %v", previews)
+	}
+
+	create := previews[0]
+	if create.Method != "POST" || create.URL != server.URL+"/dns/managed/870073/records/" {
+		t.Fatalf("bad create preview: %s", create)
+	}
+	if create.Header.Get("X-Dnsme-Apikey") != "aaaaaa1a-11a1-1aa1-a101-11a1a11aa1aa" ||
+		create.Header.Get("X-Dnsme-Hmac") == "" {
+		t.Fatalf("preview not signed: %v", create.Header)
+	}
+	sent := map[string]interface{}{}
+	if err := json.Unmarshal(create.Body, &sent); err != nil || sent["value"] != "1.1.1.2" {
+		t.Fatalf("bad create body: %s", create.Body)
+	}
+
+	update := previews[1]
+	if update.Method != "PUT" || update.URL != server.URL+"/dns/managed/870073/records/10039430/" {
+		t.Fatalf("bad update preview: %s", update)
+	}
+	if err := json.Unmarshal(update.Body, &sent); err != nil || sent["value"] != "1.1.1.3" {
+		t.Fatalf("bad update body: %s", update.Body)
+	}
+	if previews[2].String() != "DELETE "+server.URL+"/dns/managed/870073/records/10039430/" {
+		t.Fatalf("bad delete preview: %s", previews[2])
+	}
+}
@@ -0,0 +1,75 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"sync"
+)
+
+// Operation is a unit of work run by an Executor, such as a record create
+// or update.
+type Operation func(c *Client) error
+
+// Executor runs operations across a bounded number of goroutines sharing
+// one Client. Set a Limiter on the Client to keep the operations within
+// the API's rate limit.
+type Executor struct {
+	Client *Client
+
+	// Concurrency is the most operations run at once. At least one is
+	// run.
+	Concurrency int
+}
+
+// Run runs the operations and returns their errors, in the order of the
+// operations. Operations not started when ctx is done are not run, and
+// their error is ctx.Err(). The operations are given a copy of the Client
+// bound to ctx, so that those running stop waiting on the Limiter, and
+// their requests are abandoned, when ctx is done.
+func (e *Executor) Run(ctx context.Context, ops []Operation) []error {
+	workers := e.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(ops) {
+		workers = len(ops)
+	}
+
+	client := e.Client.WithContext(ctx)
+	errs := make([]error, len(ops))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = ops[i](client)
+			}
+		}()
+	}
+
+	for i := range ops {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	close(next)
+	wg.Wait()
+	return errs
+}
+
+// FirstError returns the first error that is not nil, or nil.
+func FirstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyServer answers record creates, counting the requests in
+// flight at once.
+type concurrencyServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	total    int
+}
+
+func newConcurrencyServer() *concurrencyServer {
+	s := &concurrencyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.inFlight++
+		s.total++
+		if s.inFlight > s.peak {
+			s.peak = s.inFlight
+		}
+		id := s.total
+		s.mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+		w.WriteHeader(201)
+		fmt.Fprintf(w, `{"id":%d,"type":"A"}`, id)
+	}))
+	return s
+}
+
+func TestExecutor_Run(t *testing.T) {
+	server := newConcurrencyServer()
+	defer server.Close()
+	client := makeClient(t)
+	client.URL = server.URL
+	client.Cache = NewRecordCache(time.Minute)
+
+	var ops []Operation
+	for i := 0; i < 40; i++ {
+		cr := map[string]interface{}{
+			"name":  fmt.Sprintf("host%d", i),
+			"type":  "A",
+			"value": "1.1.1.1",
+		}
+		ops = append(ops, func(c *Client) error {
+			_, err := c.CreateRecord("870073", cr)
+			return err
+		})
+	}
+
+	e := &Executor{Client: client, Concurrency: 4}
+	errs := e.Run(context.Background(), ops)
+	if err := FirstError(errs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(errs) != 40 || server.total != 40 {
+		t.Fatalf("bad number of operations: %d errors, %d requests", len(errs), server.total)
+	}
+	if server.peak > 4 {
+		t.Fatalf("too many concurrent requests: %d", server.peak)
+	}
+}
+
+func TestExecutor_RunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ops []Operation
+	for i := 0; i < 10; i++ {
+		ops = append(ops, func(c *Client) error {
+			cancel()
+			return nil
+		})
+	}
+
+	e := &Executor{Client: makeClient(t)}
+	errs := e.Run(ctx, ops)
+	if errs[0] != nil {
+		t.Fatalf("first operation should run: %v", errs[0])
+	}
+	if errs[9] != context.Canceled {
+		t.Fatalf("last operation should not run: %v", errs[9])
+	}
+}
+
+func TestRateLimiter_SharedAcrossGoroutines(t *testing.T) {
+	server := newConcurrencyServer()
+	defer server.Close()
+	limiter := NewRateLimiter(5, 100*time.Millisecond)
+
+	// Two clients share one limiter, as they share one account.
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		client := makeClient(t)
+		client.URL = server.URL
+		client.Limiter = limiter
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				client.CreateRecord("870073", map[string]interface{}{
+					"name": "host", "type": "A", "value": "1.1.1.1",
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first five are a burst, the next five wait for the bucket to
+	// refill.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("limiter did not delay requests: %s", elapsed)
+	}
+}
+
+func TestRateLimiter_ZeroValue(t *testing.T) {
+	for _, l := range []*RateLimiter{{}, NewRateLimiter(0, time.Second), NewRateLimiter(5, 0)} {
+		// A burst of DefaultRateLimit requests is allowed, and the next
+		// waits for the bucket to refill.
+		for i := 0; i < DefaultRateLimit; i++ {
+			if d := l.reserve(); d != 0 {
+				t.Fatalf("request %d delayed by %s", i, d)
+			}
+		}
+		if d := l.reserve(); d <= 0 {
+			t.Fatalf("request past the limit not delayed: %s", d)
+		}
+	}
+}
+
+func TestRateLimiter_WaitContext(t *testing.T) {
+	l := NewRateLimiter(1, time.Hour)
+	if err := l.WaitContext(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// With the bucket empty, a wait ends when its context does, and gives
+	// up its place.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("bad error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("wait not cancelled: %s", elapsed)
+	}
+	if l.tokens < -0.01 || l.tokens > 0.01 {
+		t.Fatalf("place not given up: %f tokens", l.tokens)
+	}
+}
+
+func TestExecutor_RunCancelsLimiterWait(t *testing.T) {
+	server := newConcurrencyServer()
+	defer server.Close()
+	client := makeClient(t)
+	client.URL = server.URL
+	client.Limiter = NewRateLimiter(1, time.Hour)
+
+	var ops []Operation
+	for i := 0; i < 2; i++ {
+		ops = append(ops, func(c *Client) error {
+			_, err := c.CreateRecord("870073", map[string]interface{}{
+				"name": "host", "type": "A", "value": "1.1.1.1",
+			})
+			return err
+		})
+	}
+
+	// The second operation waits on the limiter until ctx is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	e := &Executor{Client: client}
+	start := time.Now()
+	errs := e.Run(ctx, ops)
+	if errs[0] != nil {
+		t.Fatalf("first operation failed: %v", errs[0])
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("bad error: %v", errs[1])
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("limiter wait not cancelled: %s", elapsed)
+	}
+}
@@ -0,0 +1,47 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	"github.com/soniah/dnsmadeeasy/testutil"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newFakeDomain starts a FakeAPI holding one domain with the records
+// given, and returns it with a client for it and the domain's ID.
+func newFakeDomain(t *testing.T, records ...Record) (*testutil.FakeAPI, *Client, string) {
+	api := testutil.NewFakeAPI()
+	id := api.AddDomain("example.com")
+	for _, r := range records {
+		api.AddRecord(id, r)
+	}
+	client := makeClient(t)
+	client.URL = api.URL
+	return api, client, strconv.FormatInt(id, 10)
+}
+
+// fakeRecords returns the records of a domain held by a FakeAPI.
+func fakeRecords(api *testutil.FakeAPI, domainID string) []Record {
+	id, _ := strconv.ParseInt(domainID, 10, 64)
+	var records []Record
+	for _, fields := range api.Records(id) {
+		data, _ := json.Marshal(fields)
+		var r Record
+		json.Unmarshal(data, &r)
+		records = append(records, r)
+	}
+	return records
+}
+
+// fakeContents returns the records of a domain held by a FakeAPI as
+// sorted "name type value" strings.
+func fakeContents(api *testutil.FakeAPI, domainID string) string {
+	var list []string
+	for _, r := range fakeRecords(api, domainID) {
+		list = append(list, r.Name+" "+r.Type+" "+r.Value)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
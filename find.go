@@ -0,0 +1,75 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// normalizeName returns a record name in the form the API uses: lower
+// case, with the apex written as "".
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "@" {
+		return ""
+	}
+	return name
+}
+
+// SameName reports whether two record names refer to the same name. Names
+// are compared without regard to case, and "" and "@" both refer to the
+// apex.
+func SameName(a, b string) bool {
+	return normalizeName(a) == normalizeName(b)
+}
+
+// FindRecords gets the records of a domain with the name and type given.
+// An empty type matches records of any type.
+func (c *Client) FindRecords(domainID, name, rrtype string) ([]Record, error) {
+	// The API filters on name and type, but matches the apex poorly, so
+	// results are filtered again here. A cached listing of the whole
+	// domain is filtered instead of making a request.
+	var records []Record
+	var err error
+	if c.Cache != nil {
+		records, err = c.cachedRecords(domainID)
+	} else {
+		query := url.Values{}
+		if n := normalizeName(name); n != "" {
+			query.Set("recordName", n)
+		}
+		if rrtype != "" {
+			query.Set("type", strings.ToUpper(rrtype))
+		}
+		records, err = c.listRecords(domainID, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Record
+	for _, record := range records {
+		if SameName(record.Name, name) &&
+			(rrtype == "" || strings.EqualFold(record.Type, rrtype)) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// FindRecord gets the single record of a domain with the name and type
+// given. It is an error if there is no such record, or more than one.
+func (c *Client) FindRecord(domainID, name, rrtype string) (*Record, error) {
+	records, err := c.FindRecords(domainID, name, rrtype)
+	if err != nil {
+		return nil, err
+	}
+	switch len(records) {
+	case 0:
+		return nil, fmt.Errorf("Unable to find %s record %q", rrtype, name)
+	case 1:
+		return &records[0], nil
+	}
+	return nil, fmt.Errorf("Found %d %s records %q, expected one",
+		len(records), rrtype, name)
+}
@@ -0,0 +1,61 @@
+package dnsmadeeasy
+
+import . "github.com/motain/gocheck"
+
+func (s *S) Test_SameName(c *C) {
+	c.Assert(SameName("", "@"), Equals, true)
+	c.Assert(SameName("WWW", "www"), Equals, true)
+	c.Assert(SameName("www.", "www"), Equals, true)
+	c.Assert(SameName("www", "@"), Equals, false)
+}
+
+func (s *S) Test_FindRecordsGood(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	records, err := s.client.FindRecords("870073", "TEST", "a")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/dns/managed/870073/records/")
+	c.Assert(req.URL.Query().Get("recordName"), Equals, "test")
+	c.Assert(req.URL.Query().Get("type"), Equals, "A")
+	c.Assert(records, HasLen, 2)
+}
+
+func (s *S) Test_FindRecordsApex(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	records, err := s.client.FindRecords("870073", "@", "MX")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Query().Get("recordName"), Equals, "")
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RecordID, Equals, int64(10039430))
+}
+
+func (s *S) Test_FindRecord(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	record, err := s.client.FindRecord("870073", "", "MX")
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(record.Value, Equals, "mail")
+
+	testServer.Response(200, nil, recordsFind)
+	_, err = s.client.FindRecord("870073", "test", "A")
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, `Found 2 A records "test", expected one`)
+
+	testServer.Response(200, nil, recordsFind)
+	_, err = s.client.FindRecord("870073", "missing", "A")
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, `Unable to find A record "missing"`)
+}
+
+var recordsFind = `{
+  "data":[
+    {"name":"test","value":"1.1.1.1","id":10039428,"type":"A","ttl":86400},
+    {"name":"Test","value":"1.1.1.2","id":10039429,"type":"A","ttl":86400},
+    {"name":"","value":"mail","id":10039430,"type":"MX","mxLevel":10,"ttl":86400},
+    {"name":"www","value":"test","id":10039431,"type":"CNAME","ttl":86400}
+  ],
+  "page":0,
+  "totalPages":1,
+  "totalRecords":4
+}`
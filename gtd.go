@@ -0,0 +1,109 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GTDLocation is a Global Traffic Director location. Records of a GTD
+// enabled domain are only served to resolvers in their location.
+type GTDLocation string
+
+// Locations known to Global Traffic Director.
+const (
+	GTDDefault      GTDLocation = "DEFAULT"
+	GTDUSEast       GTDLocation = "US_EAST"
+	GTDUSWest       GTDLocation = "US_WEST"
+	GTDEurope       GTDLocation = "EUROPE"
+	GTDAsiaPac      GTDLocation = "ASIA_PAC"
+	GTDOceania      GTDLocation = "OCEANIA"
+	GTDSouthAmerica GTDLocation = "SOUTH_AMERICA"
+)
+
+// GTDLocations lists every location, DEFAULT first.
+var GTDLocations = []GTDLocation{
+	GTDDefault,
+	GTDUSEast,
+	GTDUSWest,
+	GTDEurope,
+	GTDAsiaPac,
+	GTDOceania,
+	GTDSouthAmerica,
+}
+
+// Valid reports whether l is a known location.
+func (l GTDLocation) Valid() bool {
+	for _, known := range GTDLocations {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGTDLocation returns the location named s. An empty string is the
+// default location.
+func ParseGTDLocation(s string) (GTDLocation, error) {
+	if s == "" {
+		return GTDDefault, nil
+	}
+	l := GTDLocation(s)
+	if !l.Valid() {
+		return "", fmt.Errorf("Invalid GTD location %q", s)
+	}
+	return l, nil
+}
+
+// Location returns the GTD location of the record.
+func (r *Record) Location() (GTDLocation, error) {
+	return ParseGTDLocation(r.GtdLocation)
+}
+
+// CreateGTDRecords creates the record described by cr once for each
+// location in values, using that location's value. It returns the IDs of
+// the records created by location. If a create fails, the records created
+// so far are returned along with the error.
+func (c *Client) CreateGTDRecords(domainID string, cr map[string]interface{},
+	values map[GTDLocation]string) (map[GTDLocation]string, error) {
+
+	locations := make([]GTDLocation, 0, len(values))
+	for l := range values {
+		if !l.Valid() {
+			return nil, fmt.Errorf("Invalid GTD location %q", l)
+		}
+		locations = append(locations, l)
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return gtdIndex(locations[i]) < gtdIndex(locations[j])
+	})
+
+	ids := map[GTDLocation]string{}
+	for _, l := range locations {
+		fields := map[string]interface{}{}
+		for k, v := range cr {
+			if strings.EqualFold(k, "value") || strings.EqualFold(k, "gtdLocation") {
+				continue
+			}
+			fields[k] = v
+		}
+		fields["gtdLocation"] = string(l)
+		fields["value"] = values[l]
+
+		id, err := c.CreateRecord(domainID, fields)
+		if err != nil {
+			return ids, fmt.Errorf("Error creating record in %s: %s", l, err)
+		}
+		ids[l] = id
+	}
+	return ids, nil
+}
+
+func gtdIndex(l GTDLocation) int {
+	for i, known := range GTDLocations {
+		if l == known {
+			return i
+		}
+	}
+	return len(GTDLocations)
+}
@@ -0,0 +1,55 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_ParseGTDLocation(c *C) {
+	l, err := ParseGTDLocation("")
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, GTDDefault)
+
+	l, err = ParseGTDLocation("ASIA_PAC")
+	c.Assert(err, IsNil)
+	c.Assert(l, Equals, GTDAsiaPac)
+
+	_, err = ParseGTDLocation("asia_pac")
+	c.Assert(err, ErrorMatches, `Invalid GTD location "asia_pac"`)
+}
+
+func (s *S) Test_CreateGTDRecords(c *C) {
+	testServer.Response(201, nil, `{"id":1,"type":"A"}`)
+	testServer.Response(201, nil, `{"id":2,"type":"A"}`)
+	cr := map[string]interface{}{
+		"name":  "www",
+		"type":  "A",
+		"Value": "ignored",
+		"ttl":   300,
+	}
+	ids, err := s.client.CreateGTDRecords("870073", cr, map[GTDLocation]string{
+		GTDEurope: "2.2.2.2",
+		GTDUSEast: "1.1.1.1",
+	})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, map[GTDLocation]string{GTDUSEast: "1", GTDEurope: "2"})
+
+	// Locations are created in the order of GTDLocations.
+	for i, want := range []string{"US_EAST", "EUROPE"} {
+		body, _ := ioutil.ReadAll(reqs[i].Body)
+		sent := map[string]interface{}{}
+		c.Assert(json.Unmarshal(body, &sent), IsNil)
+		c.Assert(sent["gtdLocation"], Equals, want)
+		_, ok := sent["Value"]
+		c.Assert(ok, Equals, false)
+	}
+}
+
+func (s *S) Test_CreateGTDRecordsBad(c *C) {
+	_, err := s.client.CreateGTDRecords("870073", nil, map[GTDLocation]string{
+		"MARS": "1.1.1.1",
+	})
+	c.Assert(err, NotNil)
+}
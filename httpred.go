@@ -0,0 +1,119 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Redirect types of an HTTPRED record.
+const (
+	RedirectPermanent   = "Standard - 301"
+	RedirectTemporary   = "Standard - 302"
+	RedirectHiddenFrame = "Hidden Frame Masked"
+)
+
+// HTTPRedirect is an HTTP redirection record. It is a typed view of the
+// redirect fields of a Record of type HTTPRED.
+type HTTPRedirect struct {
+	Name string
+
+	// URL is the target of the redirect, stored as the record's value.
+	URL string
+
+	// RedirectType is one of RedirectPermanent, RedirectTemporary or
+	// RedirectHiddenFrame.
+	RedirectType string
+
+	// Title, Keywords and Description are used as the page metadata of a
+	// hidden frame masked redirect, and must be empty for other types.
+	Title       string
+	Keywords    string
+	Description string
+
+	// HardLink redirects to the target URL only, rather than appending the
+	// path of the original request to it.
+	HardLink bool
+
+	TTL int64
+}
+
+// NewHTTPRedirect returns a redirect from name to target of the type
+// specified.
+func NewHTTPRedirect(name, target, redirectType string) *HTTPRedirect {
+	return &HTTPRedirect{
+		Name:         name,
+		URL:          target,
+		RedirectType: redirectType,
+		TTL:          86400,
+	}
+}
+
+// HTTPRedirectFromRecord returns the redirect held in an HTTPRED record.
+func HTTPRedirectFromRecord(r *Record) (*HTTPRedirect, error) {
+	if r.Type != "HTTPRED" {
+		return nil, fmt.Errorf("Record %s is of type %s, not HTTPRED",
+			r.StringRecordID(), r.Type)
+	}
+	return &HTTPRedirect{
+		Name:         r.Name,
+		URL:          r.Value,
+		RedirectType: r.RedirectType,
+		Title:        r.Title,
+		Keywords:     r.Keywords,
+		Description:  r.Description,
+		HardLink:     r.HardLink,
+		TTL:          r.TTL,
+	}, nil
+}
+
+// Validate checks the redirect follows the rules of the API.
+func (h *HTTPRedirect) Validate() error {
+	switch h.RedirectType {
+	case RedirectPermanent, RedirectTemporary:
+		if h.Title != "" || h.Keywords != "" || h.Description != "" {
+			return fmt.Errorf("Title, keywords and description are only " +
+				"used by hidden frame masked redirects")
+		}
+	case RedirectHiddenFrame:
+	default:
+		return fmt.Errorf("Invalid redirect type %q", h.RedirectType)
+	}
+
+	u, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("Invalid redirect URL %q: %s", h.URL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid redirect URL %q: must be an absolute "+
+			"http or https URL", h.URL)
+	}
+	return nil
+}
+
+// Fields returns the redirect as the fields of an HTTPRED record, suitable
+// for CreateRecord.
+func (h *HTTPRedirect) Fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":         h.Name,
+		"type":         "HTTPRED",
+		"value":        h.URL,
+		"redirectType": h.RedirectType,
+		"hardLink":     h.HardLink,
+		"ttl":          h.TTL,
+	}
+	if h.RedirectType == RedirectHiddenFrame {
+		fields["title"] = h.Title
+		fields["keywords"] = h.Keywords
+		fields["description"] = h.Description
+	}
+	return fields
+}
+
+// CreateHTTPRedirect validates and creates an HTTPRED record, returning its
+// ID.
+func (c *Client) CreateHTTPRedirect(domainID string, h *HTTPRedirect) (string, error) {
+	if err := h.Validate(); err != nil {
+		return "", err
+	}
+	return c.CreateRecord(domainID, h.Fields())
+}
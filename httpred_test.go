@@ -0,0 +1,92 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_HTTPRedirectRoundTrip(c *C) {
+	var record Record
+	c.Assert(json.Unmarshal([]byte(httpredRead), &record), IsNil)
+
+	h, err := HTTPRedirectFromRecord(&record)
+	c.Assert(err, IsNil)
+	c.Assert(h.Validate(), IsNil)
+	c.Assert(h.RedirectType, Equals, RedirectHiddenFrame)
+	c.Assert(h.Title, Equals, "Example")
+
+	// Encoding the fields and decoding them as a record gives back the
+	// redirect we started with.
+	data, err := json.Marshal(h.Fields())
+	c.Assert(err, IsNil)
+	var sent Record
+	c.Assert(json.Unmarshal(data, &sent), IsNil)
+	back, err := HTTPRedirectFromRecord(&sent)
+	c.Assert(err, IsNil)
+	c.Assert(back, DeepEquals, h)
+}
+
+func (s *S) Test_HTTPRedirectFromRecordBad(c *C) {
+	_, err := HTTPRedirectFromRecord(&Record{RecordID: 1, Type: "A"})
+	c.Assert(err, ErrorMatches, "Record 1 is of type A, not HTTPRED")
+}
+
+func (s *S) Test_HTTPRedirectValidate(c *C) {
+	h := NewHTTPRedirect("www", "https://example.net/", RedirectPermanent)
+	c.Assert(h.Validate(), IsNil)
+
+	h.URL = "example.net"
+	c.Assert(h.Validate(), ErrorMatches, "Invalid redirect URL.*")
+
+	h.URL = "https://example.net/"
+	h.RedirectType = "Standard - 307"
+	c.Assert(h.Validate(), ErrorMatches, "Invalid redirect type.*")
+
+	h.RedirectType = RedirectTemporary
+	h.Title = "Example"
+	c.Assert(h.Validate(), NotNil)
+}
+
+func (s *S) Test_CreateHTTPRedirectGood(c *C) {
+	testServer.Response(201, nil, httpredRead)
+	h := NewHTTPRedirect("www", "https://example.net/", RedirectPermanent)
+	id, err := s.client.CreateHTTPRedirect("870073", h)
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "10039500")
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent["type"], Equals, "HTTPRED")
+	c.Assert(sent["redirectType"], Equals, RedirectPermanent)
+	_, ok := sent["title"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) Test_CreateHTTPRedirectInvalid(c *C) {
+	h := NewHTTPRedirect("www", "ftp://example.net/", RedirectPermanent)
+	_, err := s.client.CreateHTTPRedirect("870073", h)
+	c.Assert(err, NotNil)
+}
+
+var httpredRead = `{
+  "name":"www",
+  "value":"https://example.net/landing",
+  "id":10039500,
+  "type":"HTTPRED",
+  "source":1,
+  "failover":false,
+  "monitor":false,
+  "sourceId":870073,
+  "dynamicDns":false,
+  "failed":false,
+  "gtdLocation":"DEFAULT",
+  "hardLink":true,
+  "ttl":1800,
+  "redirectType":"Hidden Frame Masked",
+  "title":"Example",
+  "keywords":"example, landing",
+  "description":"The example landing page"
+}`
@@ -0,0 +1,130 @@
+package dnsmadeeasy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a record change made by a Client.
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+
+	// APIKey is the key the change was made with. The secret key is never
+	// journaled.
+	APIKey string `json:"apiKey"`
+
+	// Action is "create", "update" or "delete".
+	Action   string `json:"action"`
+	DomainID string `json:"domainId"`
+	RecordID string `json:"recordId,omitempty"`
+
+	// Before is the record before the change, and After the record after
+	// it. Before is nil for a create, and After for a delete. Passwords of
+	// dynamic DNS records are left out.
+	Before *Record `json:"before,omitempty"`
+	After  *Record `json:"after,omitempty"`
+
+	// DryRun is set if the change was not sent, as the Client was in
+	// dry-run mode.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Error is the reason the change failed, or empty if it was made.
+	Error string `json:"error,omitempty"`
+}
+
+// Journal is written an entry for every record change made by a Client.
+// Implementations must be safe for concurrent use.
+type Journal interface {
+	Write(e *JournalEntry) error
+}
+
+// FileJournal is a Journal that appends entries to a file as JSON lines.
+type FileJournal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenFileJournal opens the file at path for appending entries, creating
+// it if it does not exist.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{f: f}, nil
+}
+
+// Write appends an entry to the file. Each entry is written with a single
+// write, so entries are not interleaved.
+func (j *FileJournal) Write(e *JournalEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.f.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (j *FileJournal) Close() error {
+	return j.f.Close()
+}
+
+// ReadJournal reads the entries written by a FileJournal.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// journal writes an entry for a change to the Journal, if there is one.
+// The change has already been made, or has failed, so a failure to write
+// the entry is logged rather than returned.
+func (c *Client) journal(action, domainID, recordID string, before, after *Record, err error) {
+	if c.Journal == nil {
+		return
+	}
+	e := &JournalEntry{
+		Time:     time.Now().UTC(),
+		APIKey:   c.AKey,
+		Action:   action,
+		DomainID: domainID,
+		RecordID: recordID,
+		Before:   withoutPassword(before),
+		After:    withoutPassword(after),
+		DryRun:   c.DryRun,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if err := c.Journal.Write(e); err != nil {
+		log.Printf("dnsmadeeasy: error writing journal: %s", err)
+	}
+}
+
+func withoutPassword(r *Record) *Record {
+	if r == nil {
+		return nil
+	}
+	stripped := *r
+	stripped.Password = ""
+	return &stripped
+}
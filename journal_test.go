@@ -0,0 +1,78 @@
+package dnsmadeeasy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClient_Journal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"data":[{"id":10039430,"name":"www","type":"A","value":"1.1.1.1",
+				"ttl":300,"dynamicDns":true,"password":"secret"}],"totalPages":1}`))
+		case "POST":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"id":10039431,"name":"test","type":"A","value":"1.1.1.2","ttl":300}`))
+		case "DELETE":
+			w.WriteHeader(404)
+		default:
+			w.WriteHeader(200)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	client := makeClient(t)
+	client.URL = server.URL
+	client.Journal = journal
+
+	client.CreateRecord("870073", map[string]interface{}{
+		"name": "test", "type": "A", "value": "1.1.1.2", "ttl": 300,
+	})
+	client.UpdateRecord("870073", "10039430", map[string]interface{}{"value": "1.1.1.3"})
+	client.DeleteRecord("870073", "10039430")
+	if err := journal.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+	entries, err := ReadJournal(f)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("bad entries: %#v", entries)
+	}
+	for _, e := range entries {
+		if e.APIKey != client.AKey || e.DomainID != "870073" || e.Time.IsZero() {
+			t.Fatalf("bad entry: %#v", e)
+		}
+	}
+
+	create, update, del := entries[0], entries[1], entries[2]
+	if create.Action != "create" || create.RecordID != "10039431" || create.Before != nil ||
+		create.After.Value != "1.1.1.2" || create.Error != "" {
+		t.Fatalf("bad create entry: %#v", create)
+	}
+	if update.Action != "update" || update.Before.Value != "1.1.1.1" || update.After.Value != "1.1.1.3" {
+		t.Fatalf("bad update entry: %#v", update)
+	}
+	if update.Before.Password != "" || update.After.Password != "" {
+		t.Fatalf("password journaled: %#v", update)
+	}
+	if del.Action != "delete" || del.Before.Value != "1.1.1.1" || del.After != nil || del.Error != "Not found" {
+		t.Fatalf("bad delete entry: %#v", del)
+	}
+}
@@ -0,0 +1,99 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The API allows each account DefaultRateLimit requests every
+// DefaultRatePeriod.
+const (
+	DefaultRateLimit  = 150
+	DefaultRatePeriod = 5 * time.Minute
+)
+
+// RateLimiter limits the rate of requests with a token bucket. It starts
+// full, so a burst of up to the limit is sent at once, then refills
+// steadily. It is safe for concurrent use. The zero value allows
+// DefaultRateLimit requests every DefaultRatePeriod.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  float64
+	rate   float64 // tokens per second
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter that allows n requests every period.
+// If n or period is not positive, the defaults are used.
+func NewRateLimiter(n int, period time.Duration) *RateLimiter {
+	l := &RateLimiter{}
+	l.init(n, period)
+	return l
+}
+
+func (l *RateLimiter) init(n int, period time.Duration) {
+	if n <= 0 || period <= 0 {
+		n, period = DefaultRateLimit, DefaultRatePeriod
+	}
+	l.limit = float64(n)
+	l.rate = float64(n) / period.Seconds()
+	l.tokens = float64(n)
+	l.last = time.Now()
+}
+
+// Wait blocks until a request may be sent.
+func (l *RateLimiter) Wait() {
+	l.WaitContext(context.Background())
+}
+
+// WaitContext blocks until a request may be sent, or ctx is done. If ctx
+// is done first, the request's place is given up and ctx.Err() is
+// returned.
+func (l *RateLimiter) WaitContext(ctx context.Context) error {
+	d := l.reserve()
+	if d == 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is
+// available. Tokens may be taken before they are available, so that
+// waiters are served in turn.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate == 0 {
+		l.init(DefaultRateLimit, DefaultRatePeriod)
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.limit {
+		l.tokens = l.limit
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// release gives back a token taken by reserve but not used.
+func (l *RateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
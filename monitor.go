@@ -0,0 +1,199 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Protocols that can be used by a monitor.
+const (
+	ProtocolTCP   int64 = 1
+	ProtocolUDP   int64 = 2
+	ProtocolHTTP  int64 = 3
+	ProtocolDNS   int64 = 4
+	ProtocolSMTP  int64 = 5
+	ProtocolHTTPS int64 = 6
+)
+
+// MaxFailoverIPs is the number of failover IPs a monitor can hold.
+const MaxFailoverIPs = 5
+
+// Monitor is the system monitoring and failover configuration of an A
+// record.
+type Monitor struct {
+	RecordID          int64
+	Monitor           bool
+	Failover          bool
+	AutoFailover      bool
+	Sensitivity       int64
+	ProtocolID        int64
+	Port              int64
+	MaxEmails         int64
+	SystemDescription string
+	ContactListID     int64
+	HTTPFqdn          string
+	HTTPFile          string
+	HTTPQueryString   string
+	Source            int64
+	SourceID          int64
+
+	// ContactListName, if set, is resolved to ContactListID by
+	// UpdateMonitor, so that lists can be referred to by name.
+	ContactListName string
+
+	// IPs are the failover IPs, in order. At most MaxFailoverIPs may be
+	// given.
+	IPs []string
+}
+
+// monitorJSON is the wire format of a Monitor.
+type monitorJSON struct {
+	RecordID          int64  `json:"recordId,omitempty"`
+	Monitor           bool   `json:"monitor"`
+	Failover          bool   `json:"failover"`
+	AutoFailover      bool   `json:"autoFailover"`
+	Sensitivity       int64  `json:"sensitivity,omitempty"`
+	ProtocolID        int64  `json:"protocolId,omitempty"`
+	Port              int64  `json:"port,omitempty"`
+	MaxEmails         int64  `json:"maxEmails,omitempty"`
+	SystemDescription string `json:"systemDescription,omitempty"`
+	ContactListID     int64  `json:"contactListId,omitempty"`
+	HTTPFqdn          string `json:"httpFqdn,omitempty"`
+	HTTPFile          string `json:"httpFile,omitempty"`
+	HTTPQueryString   string `json:"httpQueryString,omitempty"`
+	Source            int64  `json:"source,omitempty"`
+	SourceID          int64  `json:"sourceId,omitempty"`
+	IP1               string `json:"ip1,omitempty"`
+	IP2               string `json:"ip2,omitempty"`
+	IP3               string `json:"ip3,omitempty"`
+	IP4               string `json:"ip4,omitempty"`
+	IP5               string `json:"ip5,omitempty"`
+}
+
+// MarshalJSON encodes the monitor in the format the API expects, with the
+// failover IPs flattened into ip1 to ip5.
+func (m Monitor) MarshalJSON() ([]byte, error) {
+	if len(m.IPs) > MaxFailoverIPs {
+		return nil, fmt.Errorf("Too many failover IPs: %d, maximum is %d",
+			len(m.IPs), MaxFailoverIPs)
+	}
+	w := monitorJSON{
+		RecordID:          m.RecordID,
+		Monitor:           m.Monitor,
+		Failover:          m.Failover,
+		AutoFailover:      m.AutoFailover,
+		Sensitivity:       m.Sensitivity,
+		ProtocolID:        m.ProtocolID,
+		Port:              m.Port,
+		MaxEmails:         m.MaxEmails,
+		SystemDescription: m.SystemDescription,
+		ContactListID:     m.ContactListID,
+		HTTPFqdn:          m.HTTPFqdn,
+		HTTPFile:          m.HTTPFile,
+		HTTPQueryString:   m.HTTPQueryString,
+		Source:            m.Source,
+		SourceID:          m.SourceID,
+	}
+	ips := []*string{&w.IP1, &w.IP2, &w.IP3, &w.IP4, &w.IP5}
+	for i, ip := range m.IPs {
+		*ips[i] = ip
+	}
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON decodes a monitor returned by the API, collecting ip1 to
+// ip5 into IPs.
+func (m *Monitor) UnmarshalJSON(data []byte) error {
+	var w monitorJSON
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*m = Monitor{
+		RecordID:          w.RecordID,
+		Monitor:           w.Monitor,
+		Failover:          w.Failover,
+		AutoFailover:      w.AutoFailover,
+		Sensitivity:       w.Sensitivity,
+		ProtocolID:        w.ProtocolID,
+		Port:              w.Port,
+		MaxEmails:         w.MaxEmails,
+		SystemDescription: w.SystemDescription,
+		ContactListID:     w.ContactListID,
+		HTTPFqdn:          w.HTTPFqdn,
+		HTTPFile:          w.HTTPFile,
+		HTTPQueryString:   w.HTTPQueryString,
+		Source:            w.Source,
+		SourceID:          w.SourceID,
+	}
+	for _, ip := range []string{w.IP1, w.IP2, w.IP3, w.IP4, w.IP5} {
+		if ip != "" {
+			m.IPs = append(m.IPs, ip)
+		}
+	}
+	return nil
+}
+
+func monitorEndpoint(recordID string) string {
+	return fmt.Sprintf("/monitor/%s", recordID)
+}
+
+// GetMonitor gets the monitor configuration of the record specified.
+func (c *Client) GetMonitor(recordID string) (*Monitor, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", monitorEndpoint(recordID), body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving monitor: %s", err)
+	}
+
+	monitor := new(Monitor)
+	err = decodeBody(resp, monitor)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing monitor response: %s", err)
+	}
+	return monitor, nil
+}
+
+// UpdateMonitor replaces the monitor configuration of the record specified
+// and returns an error if it fails.
+func (c *Client) UpdateMonitor(recordID string, m *Monitor) error {
+	if m.ContactListName != "" {
+		list, err := c.ContactListByName(m.ContactListName)
+		if err != nil {
+			return err
+		}
+		resolved := *m
+		resolved.ContactListID = list.ID
+		m = &resolved
+	}
+
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	req, err := c.NewRequest("PUT", monitorEndpoint(recordID), buf, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = checkResp(c.do(req))
+	if err != nil {
+		return fmt.Errorf("Error updating monitor: %s", err)
+	}
+
+	// The request was successful
+	return nil
+}
+
+// DisableMonitor turns off both monitoring and failover for the record
+// specified.
+func (c *Client) DisableMonitor(recordID string) error {
+	return c.UpdateMonitor(recordID, &Monitor{})
+}
@@ -0,0 +1,93 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_GetMonitorGood(c *C) {
+	testServer.Response(200, nil, monitorRead)
+	monitor, err := s.client.GetMonitor("10039429")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/monitor/10039429")
+	c.Assert(monitor.RecordID, Equals, int64(10039429))
+	c.Assert(monitor.ProtocolID, Equals, ProtocolHTTP)
+	c.Assert(monitor.IPs, DeepEquals, []string{"1.1.1.2", "1.1.1.3"})
+	c.Assert(monitor.HTTPFqdn, Equals, "www.example.com")
+}
+
+func (s *S) Test_GetMonitorBad(c *C) {
+	testServer.Response(404, nil, "")
+	monitor, err := s.client.GetMonitor("100394")
+	_ = testServer.WaitRequest()
+	c.Assert(err, NotNil)
+	c.Assert(monitor, IsNil)
+}
+
+func (s *S) Test_UpdateMonitorGood(c *C) {
+	testServer.Response(200, nil, "")
+	m := &Monitor{
+		Monitor:     true,
+		Failover:    true,
+		ProtocolID:  ProtocolTCP,
+		Port:        443,
+		Sensitivity: 5,
+		IPs:         []string{"1.1.1.2", "1.1.1.3", "1.1.1.4"},
+	}
+	err := s.client.UpdateMonitor("10039429", m)
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.Method, Equals, "PUT")
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent["ip3"], Equals, "1.1.1.4")
+	c.Assert(sent["port"], Equals, float64(443))
+	_, ok := sent["ip4"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) Test_UpdateMonitorTooManyIPs(c *C) {
+	m := &Monitor{IPs: []string{"1.1.1.1", "1.1.1.2", "1.1.1.3",
+		"1.1.1.4", "1.1.1.5", "1.1.1.6"}}
+	err := s.client.UpdateMonitor("10039429", m)
+	c.Assert(err, NotNil)
+}
+
+func (s *S) Test_DisableMonitor(c *C) {
+	testServer.Response(200, nil, "")
+	err := s.client.DisableMonitor("10039429")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+
+	body, _ := ioutil.ReadAll(req.Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent["monitor"], Equals, false)
+	c.Assert(sent["failover"], Equals, false)
+}
+
+var monitorRead = `{
+  "recordId":10039429,
+  "monitor":true,
+  "failover":true,
+  "autoFailover":false,
+  "sensitivity":5,
+  "protocolId":3,
+  "port":80,
+  "maxEmails":1,
+  "systemDescription":"web",
+  "contactListId":3391,
+  "httpFqdn":"www.example.com",
+  "httpFile":"/health",
+  "httpQueryString":"ok",
+  "source":1,
+  "sourceId":870073,
+  "ip1":"1.1.1.2",
+  "ip2":"1.1.1.3",
+  "ip1Failed":0,
+  "ip2Failed":0
+}`
@@ -0,0 +1,54 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Owner records are TXT records marking which tool owns the records of a
+// name, so that tools sharing a domain do not change each other's records.
+// The owner of a name is held in a TXT record named _owner.<name>.
+
+// OwnerRecordPrefix starts the names of owner records.
+const OwnerRecordPrefix = "_owner"
+
+// OwnerRecordName returns the name of the owner record of a name.
+func OwnerRecordName(name string) string {
+	if name = normalizeName(name); name == "" {
+		return OwnerRecordPrefix
+	}
+	return OwnerRecordPrefix + "." + name
+}
+
+// IsOwnerRecord reports whether a record is named as an owner record.
+func IsOwnerRecord(r *Record) bool {
+	name := normalizeName(r.Name)
+	return strings.EqualFold(r.Type, "TXT") &&
+		(name == OwnerRecordPrefix || strings.HasPrefix(name, OwnerRecordPrefix+"."))
+}
+
+// OwnerRecord returns the owner record marking ownerID as the owner of a
+// name.
+func OwnerRecord(name, ownerID string) Record {
+	return Record{
+		Name:        OwnerRecordName(name),
+		Type:        "TXT",
+		Value:       fmt.Sprintf(`"owner=%s"`, ownerID),
+		TTL:         300,
+		GtdLocation: string(GTDDefault),
+	}
+}
+
+// RecordOwner returns the name whose owner a record holds, and the owner,
+// if the record is an owner record.
+func RecordOwner(r *Record) (name, owner string, ok bool) {
+	if !IsOwnerRecord(r) {
+		return "", "", false
+	}
+	value := strings.Trim(r.Value, `"`)
+	if !strings.HasPrefix(value, "owner=") {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(normalizeName(r.Name), OwnerRecordPrefix), ".")
+	return name, strings.TrimPrefix(value, "owner="), true
+}
@@ -0,0 +1,28 @@
+package dnsmadeeasy
+
+import (
+	"testing"
+)
+
+func TestOwnerRecord(t *testing.T) {
+	for _, name := range []string{"www", "WWW.", "", "@"} {
+		r := OwnerRecord(name, "deployer")
+		owned, owner, ok := RecordOwner(&r)
+		if !ok || owner != "deployer" || !SameName(owned, name) {
+			t.Fatalf("bad owner of %q: %q %q %v", name, owned, owner, ok)
+		}
+		if err := r.Validate(); err != nil {
+			t.Fatalf("invalid owner record for %q: %v", name, err)
+		}
+	}
+
+	for _, r := range []Record{
+		{Name: "_owner.www", Type: "A", Value: "1.1.1.1"},
+		{Name: "_owner.www", Type: "TXT", Value: "v=spf1 -all"},
+		{Name: "_ownership", Type: "TXT", Value: `"owner=deployer"`},
+	} {
+		if _, _, ok := RecordOwner(&r); ok {
+			t.Fatalf("not an owner record: %#v", r)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"fmt"
+	"github.com/miekg/dns"
+	"net"
+	"strings"
+	"time"
+)
+
+// Defaults used by PropagationChecker when its fields are unset.
+const (
+	DefaultPropagationInterval = 5 * time.Second
+	DefaultQueryTimeout        = 2 * time.Second
+)
+
+// PropagationChecker polls nameservers directly until they all serve a
+// record. Queries are made over UDP, falling back to TCP when a response
+// is truncated.
+type PropagationChecker struct {
+	// Nameservers to query, as host or host:port. If empty, the
+	// authoritative nameservers of the name's zone are looked up.
+	Nameservers []string
+
+	// Resolvers used to look up the authoritative nameservers, as host or
+	// host:port. The system resolvers will be used if not provided.
+	Resolvers []string
+
+	// Interval between rounds of queries.
+	Interval time.Duration
+
+	// Timeout of each query.
+	Timeout time.Duration
+}
+
+// WaitForPropagation waits until the authoritative nameservers of fqdn
+// serve a record of type rrtype with the expected value, using a
+// PropagationChecker with default settings.
+func WaitForPropagation(ctx context.Context, fqdn, rrtype, expected string) error {
+	return new(PropagationChecker).WaitForPropagation(ctx, fqdn, rrtype, expected)
+}
+
+// PropagationChecker returns a checker that queries the nameservers of the
+// domain specified.
+func (c *Client) PropagationChecker(domainID string) (*PropagationChecker, error) {
+	domain, err := c.ReadDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+	checker := new(PropagationChecker)
+	for _, ns := range domain.NameServers {
+		checker.Nameservers = append(checker.Nameservers, ns.Fqdn)
+	}
+	return checker, nil
+}
+
+// WaitForPropagation waits until every nameserver serves a record of type
+// rrtype for fqdn with the expected value, or ctx is done.
+func (p *PropagationChecker) WaitForPropagation(ctx context.Context, fqdn, rrtype, expected string) error {
+	qtype, ok := dns.StringToType[strings.ToUpper(rrtype)]
+	if !ok {
+		return fmt.Errorf("Unknown record type %s", rrtype)
+	}
+	fqdn = dns.Fqdn(fqdn)
+
+	nameservers := p.Nameservers
+	if len(nameservers) == 0 {
+		var err error
+		nameservers, err = p.lookupNameservers(ctx, fqdn)
+		if err != nil {
+			return err
+		}
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultPropagationInterval
+	}
+
+	// Nameservers are dropped from pending once they serve the record.
+	pending := map[string]error{}
+	for _, ns := range nameservers {
+		pending[withPort(ns)] = nil
+	}
+	for {
+		for ns := range pending {
+			found, err := p.serves(ctx, ns, fqdn, qtype, expected)
+			if found {
+				delete(pending, ns)
+			} else {
+				pending[ns] = err
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			var waiting []string
+			for ns, err := range pending {
+				if err != nil {
+					waiting = append(waiting, fmt.Sprintf("%s (%s)", ns, err))
+				} else {
+					waiting = append(waiting, ns)
+				}
+			}
+			return fmt.Errorf("Record %s %s not propagated to %s: %s", fqdn,
+				rrtype, strings.Join(waiting, ", "), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// serves reports whether the nameserver answers the query with a record
+// holding the expected value.
+func (p *PropagationChecker) serves(ctx context.Context, ns, fqdn string,
+	qtype uint16, expected string) (bool, error) {
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(fqdn, qtype)
+	msg.RecursionDesired = false
+
+	resp, err := p.exchange(ctx, msg, ns)
+	if err != nil {
+		return false, err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return false, fmt.Errorf("%s", dns.RcodeToString[resp.Rcode])
+	}
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == qtype && strings.EqualFold(rr.Header().Name, fqdn) &&
+			valueMatches(rr, expected) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// exchange sends msg over UDP, and again over TCP if the response was
+// truncated.
+func (p *PropagationChecker) exchange(ctx context.Context, msg *dns.Msg, server string) (*dns.Msg, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	client := &dns.Client{Net: "udp", Timeout: timeout}
+	resp, _, err := client.ExchangeContext(ctx, msg, server)
+	if err == nil && !resp.Truncated {
+		return resp, nil
+	}
+	client.Net = "tcp"
+	resp, _, err = client.ExchangeContext(ctx, msg, server)
+	return resp, err
+}
+
+// lookupNameservers finds the authoritative nameservers of the zone holding
+// fqdn, by asking the resolvers for NS records of fqdn and each of its
+// parents in turn.
+func (p *PropagationChecker) lookupNameservers(ctx context.Context, fqdn string) ([]string, error) {
+	resolvers := p.Resolvers
+	if len(resolvers) == 0 {
+		conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil {
+			return nil, fmt.Errorf("Error reading resolver configuration: %s", err)
+		}
+		for _, server := range conf.Servers {
+			resolvers = append(resolvers, net.JoinHostPort(server, conf.Port))
+		}
+	}
+
+	var lastErr error
+	labels := dns.SplitDomainName(fqdn)
+	for i := range labels {
+		zone := dns.Fqdn(strings.Join(labels[i:], "."))
+		msg := new(dns.Msg)
+		msg.SetQuestion(zone, dns.TypeNS)
+		for _, resolver := range resolvers {
+			resp, err := p.exchange(ctx, msg, withPort(resolver))
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			var nameservers []string
+			for _, rr := range resp.Answer {
+				if ns, ok := rr.(*dns.NS); ok {
+					nameservers = append(nameservers, ns.Ns)
+				}
+			}
+			if len(nameservers) > 0 {
+				return nameservers, nil
+			}
+			break
+		}
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("Error looking up nameservers of %s: %s", fqdn, lastErr)
+	}
+	return nil, fmt.Errorf("Unable to find nameservers of %s", fqdn)
+}
+
+// valueMatches reports whether rr holds the value expected, written as it
+// would be given to CreateRecord.
+func valueMatches(rr dns.RR, expected string) bool {
+	switch rr := rr.(type) {
+	case *dns.A:
+		return rr.A.Equal(net.ParseIP(expected))
+	case *dns.AAAA:
+		return rr.AAAA.Equal(net.ParseIP(expected))
+	case *dns.CNAME:
+		return sameHost(rr.Target, expected)
+	case *dns.NS:
+		return sameHost(rr.Ns, expected)
+	case *dns.PTR:
+		return sameHost(rr.Ptr, expected)
+	case *dns.MX:
+		return sameHost(rr.Mx, expected)
+	case *dns.SRV:
+		return sameHost(rr.Target, expected)
+	case *dns.TXT:
+		return strings.Join(rr.Txt, "") == strings.Trim(expected, `"`)
+	}
+	data := strings.TrimPrefix(rr.String(), rr.Header().String())
+	return data == expected
+}
+
+// sameHost compares host names. A name without a trailing dot matches any
+// name it is a prefix of, as record values may be relative to the domain.
+func sameHost(served, expected string) bool {
+	served = strings.ToLower(served)
+	expected = strings.ToLower(expected)
+	if strings.HasSuffix(expected, ".") {
+		return served == expected
+	}
+	return served == expected+"." || strings.HasPrefix(served, expected+".")
+}
+
+func withPort(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "53")
+}
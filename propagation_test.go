@@ -0,0 +1,160 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"github.com/miekg/dns"
+	. "github.com/motain/gocheck"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dnsServer is an in-process authoritative nameserver that answers from a
+// set of records that tests can change.
+type dnsServer struct {
+	addr     string
+	udp, tcp *dns.Server
+
+	mu       sync.Mutex
+	records  []dns.RR
+	truncate bool
+	tcpSeen  bool
+}
+
+func startDNSServer(c *C) *dnsServer {
+	s := &dnsServer{}
+
+	// The checker falls back to TCP on the same port, which may already be
+	// in use for TCP, so ports are tried until one is free for both.
+	var pc net.PacketConn
+	var l net.Listener
+	var err error
+	for try := 0; try < 20; try++ {
+		pc, err = net.ListenPacket("udp", "127.0.0.1:0")
+		c.Assert(err, IsNil)
+		l, err = net.Listen("tcp", pc.LocalAddr().String())
+		if err == nil {
+			break
+		}
+		pc.Close()
+	}
+	c.Assert(err, IsNil)
+	s.addr = pc.LocalAddr().String()
+
+	started := make(chan bool, 2)
+	notify := func() { started <- true }
+	s.udp = &dns.Server{PacketConn: pc, Handler: s, NotifyStartedFunc: notify}
+	s.tcp = &dns.Server{Listener: l, Handler: s, NotifyStartedFunc: notify}
+	go s.udp.ActivateAndServe()
+	go s.tcp.ActivateAndServe()
+	<-started
+	<-started
+	return s
+}
+
+func (s *dnsServer) stop() {
+	s.udp.Shutdown()
+	s.tcp.Shutdown()
+}
+
+func (s *dnsServer) set(records ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = nil
+	for _, r := range records {
+		rr, _ := dns.NewRR(r)
+		s.records = append(s.records, rr)
+	}
+}
+
+func (s *dnsServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	resp.Authoritative = true
+
+	_, isTCP := w.RemoteAddr().(*net.TCPAddr)
+	if isTCP {
+		s.tcpSeen = true
+	}
+	if s.truncate && !isTCP {
+		resp.Truncated = true
+		w.WriteMsg(resp)
+		return
+	}
+
+	q := req.Question[0]
+	for _, rr := range s.records {
+		if rr.Header().Rrtype == q.Qtype && strings.EqualFold(rr.Header().Name, q.Name) {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+	w.WriteMsg(resp)
+}
+
+func (s *S) Test_WaitForPropagation(c *C) {
+	server := startDNSServer(c)
+	defer server.stop()
+	server.set("www.example.com. 300 IN A 1.1.1.1")
+
+	checker := &PropagationChecker{
+		Nameservers: []string{server.addr},
+		Interval:    10 * time.Millisecond,
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.set("www.example.com. 300 IN A 1.1.1.1", "www.example.com. 300 IN A 1.1.1.2")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := checker.WaitForPropagation(ctx, "www.example.com", "A", "1.1.1.2")
+	c.Assert(err, IsNil)
+}
+
+func (s *S) Test_WaitForPropagationTimeout(c *C) {
+	server := startDNSServer(c)
+	defer server.stop()
+	server.set(`_acme-challenge.example.com. 120 IN TXT "old"`)
+
+	checker := &PropagationChecker{
+		Nameservers: []string{server.addr},
+		Interval:    10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := checker.WaitForPropagation(ctx, "_acme-challenge.example.com", "TXT", "new")
+	c.Assert(err, ErrorMatches, "Record _acme-challenge.example.com. TXT not propagated to 127.0.0.1:.*")
+}
+
+func (s *S) Test_WaitForPropagationTCPFallback(c *C) {
+	server := startDNSServer(c)
+	defer server.stop()
+	server.set("example.com. 300 IN MX 10 mail.example.com.")
+	server.mu.Lock()
+	server.truncate = true
+	server.mu.Unlock()
+
+	checker := &PropagationChecker{Nameservers: []string{server.addr}}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err := checker.WaitForPropagation(ctx, "example.com.", "MX", "mail")
+	c.Assert(err, IsNil)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	c.Assert(server.tcpSeen, Equals, true)
+}
+
+func (s *S) Test_WaitForPropagationLookupNameservers(c *C) {
+	server := startDNSServer(c)
+	defer server.stop()
+	server.set("example.com. 300 IN NS ns1.example.com.",
+		"www.example.com. 300 IN CNAME web.example.net.")
+
+	checker := &PropagationChecker{Resolvers: []string{server.addr}}
+	nameservers, err := checker.lookupNameservers(context.Background(), "www.example.com.")
+	c.Assert(err, IsNil)
+	c.Assert(nameservers, DeepEquals, []string{"ns1.example.com."})
+}
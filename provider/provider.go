@@ -0,0 +1,334 @@
+// Package provider publishes DNS records for controllers in the style of
+// external-DNS, which list the record sets of a zone and apply changes to
+// them.
+//
+// The names of record sets created by a Provider are marked as owned by it
+// with owner records, as made by dnsmadeeasy.OwnerRecord, and a Provider
+// only changes or deletes record sets of names it owns, so that records
+// managed by hand, or by another controller or tool, are not clobbered.
+package provider
+
+import (
+	"context"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"sort"
+	"strings"
+)
+
+// DefaultTTL is the TTL of records created from endpoints without one.
+const DefaultTTL = 300
+
+// OwnerLabel is the label of an Endpoint holding the ID of its owner.
+const OwnerLabel = "owner"
+
+// Endpoint is a record set: the records of a name and type.
+type Endpoint struct {
+	// DNSName is the fully qualified name, without a trailing dot.
+	DNSName    string
+	RecordType string
+	Targets    []string
+	TTL        int64
+
+	// Labels hold the owner of the record set, if it has one.
+	Labels map[string]string
+}
+
+func (e *Endpoint) String() string {
+	return fmt.Sprintf("%s %s %s", e.DNSName, e.RecordType, strings.Join(e.Targets, ","))
+}
+
+// Changes are the changes to make to a zone. UpdateOld and UpdateNew are
+// the record sets to update, before and after, in the same order.
+type Changes struct {
+	Create    []*Endpoint
+	UpdateOld []*Endpoint
+	UpdateNew []*Endpoint
+	Delete    []*Endpoint
+}
+
+// Provider manages the record sets of one zone for one owner.
+type Provider struct {
+	Client   *dme.Client
+	Zone     string
+	DomainID string
+
+	// OwnerID identifies the controller. Each controller sharing a zone
+	// needs its own.
+	OwnerID string
+}
+
+// New returns a Provider for the zone, owning record sets as ownerID.
+func New(c *dme.Client, zone, ownerID string) (*Provider, error) {
+	if ownerID == "" {
+		return nil, fmt.Errorf("An owner ID is required")
+	}
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	domains, err := c.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+	for i := range domains {
+		if strings.EqualFold(domains[i].Name, zone) {
+			return &Provider{Client: c, Zone: zone, DomainID: domains[i].StringID(), OwnerID: ownerID}, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find domain %q", zone)
+}
+
+type setKey struct{ name, rrtype string }
+
+// zoneState is the record sets of the zone, and the owners of their names.
+type zoneState struct {
+	sets         map[setKey][]dme.Record
+	order        []setKey
+	owners       map[string]string
+	ownerRecords map[string]dme.Record
+}
+
+func (p *Provider) state() (*zoneState, error) {
+	records, err := p.Client.ListRecords(p.DomainID)
+	if err != nil {
+		return nil, err
+	}
+	s := &zoneState{
+		sets:         map[setKey][]dme.Record{},
+		owners:       map[string]string{},
+		ownerRecords: map[string]dme.Record{},
+	}
+	for _, r := range records {
+		if name, owner, ok := dme.RecordOwner(&r); ok {
+			s.owners[name] = owner
+			s.ownerRecords[name] = r
+			continue
+		}
+		k := setKey{strings.ToLower(r.Name), strings.ToUpper(r.Type)}
+		if _, ok := s.sets[k]; !ok {
+			s.order = append(s.order, k)
+		}
+		s.sets[k] = append(s.sets[k], r)
+	}
+	return s, nil
+}
+
+// hasRecords reports whether a name has any record sets.
+func (s *zoneState) hasRecords(name string) bool {
+	for k := range s.sets {
+		if k.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Records returns the record sets of the zone. Those with an owner have
+// it in their OwnerLabel.
+func (p *Provider) Records(ctx context.Context) ([]*Endpoint, error) {
+	s, err := p.state()
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []*Endpoint
+	for _, k := range s.order {
+		records := s.sets[k]
+		e := &Endpoint{
+			DNSName:    p.fqdn(k.name),
+			RecordType: k.rrtype,
+			TTL:        records[0].TTL,
+			Labels:     map[string]string{},
+		}
+		for _, r := range records {
+			e.Targets = append(e.Targets, r.Value)
+		}
+		sort.Strings(e.Targets)
+		if owner, ok := s.owners[k.name]; ok {
+			e.Labels[OwnerLabel] = owner
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+// fqdn returns the fully qualified form of a name relative to the zone.
+func (p *Provider) fqdn(name string) string {
+	if name == "" {
+		return p.Zone
+	}
+	return name + "." + p.Zone
+}
+
+// relative returns the name of an endpoint relative to the zone.
+func (p *Provider) relative(e *Endpoint) (string, error) {
+	name := strings.ToLower(strings.TrimSuffix(e.DNSName, "."))
+	if name == p.Zone {
+		return "", nil
+	}
+	if !strings.HasSuffix(name, "."+p.Zone) {
+		return "", fmt.Errorf("%s is not in zone %s", e.DNSName, p.Zone)
+	}
+	return strings.TrimSuffix(name, "."+p.Zone), nil
+}
+
+func (p *Provider) key(e *Endpoint) (setKey, error) {
+	name, err := p.relative(e)
+	return setKey{name, strings.ToUpper(e.RecordType)}, err
+}
+
+// ApplyChanges applies the changes to the zone. Every change is checked
+// before any is made: creating a record set at a name owned by someone
+// else, or at a name without an owner that already has records, fails, as
+// does changing or deleting a record set of a name not owned by the
+// Provider. Updates and deletes are made first, then every record created
+// is made in one bulk create; if anything fails, the changes already made
+// are rolled back.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *Changes) error {
+	if len(changes.UpdateOld) != len(changes.UpdateNew) {
+		return fmt.Errorf("UpdateOld and UpdateNew differ in length")
+	}
+	s, err := p.state()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	check := func(e *Endpoint, mustOwn bool) setKey {
+		k, err := p.key(e)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return k
+		}
+		owner, owned := s.owners[k.name]
+		switch {
+		case mustOwn && owner != p.OwnerID:
+			problems = append(problems, fmt.Sprintf("%s %s is not owned by %s", e.DNSName, e.RecordType, p.OwnerID))
+		case !mustOwn && owned && owner != p.OwnerID:
+			problems = append(problems, fmt.Sprintf("%s is owned by %s, not %s", e.DNSName, owner, p.OwnerID))
+		case !mustOwn && !owned && s.hasRecords(k.name):
+			problems = append(problems, fmt.Sprintf("%s already has records and is not owned by %s", e.DNSName, p.OwnerID))
+		}
+		return k
+	}
+	for _, e := range changes.Create {
+		check(e, false)
+	}
+	for i := range changes.UpdateOld {
+		before := check(changes.UpdateOld[i], true)
+		if after := check(changes.UpdateNew[i], true); after != before {
+			problems = append(problems, fmt.Sprintf("Update of %s %s changes its name or type",
+				changes.UpdateOld[i].DNSName, changes.UpdateOld[i].RecordType))
+		}
+	}
+	for _, e := range changes.Delete {
+		check(e, true)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Unable to apply changes: %s", strings.Join(problems, "; "))
+	}
+
+	cs := p.Client.NewChangeSet(p.DomainID)
+	var create []dme.Record
+	fail := func(err error) error {
+		if rerr := cs.Rollback(); rerr != nil {
+			return fmt.Errorf("%s; rollback failed: %s", err, rerr)
+		}
+		return err
+	}
+
+	for _, e := range changes.Delete {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		k := mustKey(p, e)
+		for _, r := range s.sets[k] {
+			if err := cs.Delete(r.StringRecordID()); err != nil {
+				return fail(err)
+			}
+		}
+		delete(s.sets, k)
+
+		// The owner record goes with the last record set of the name.
+		if r, ok := s.ownerRecords[k.name]; ok && !s.hasRecords(k.name) {
+			if err := cs.Delete(r.StringRecordID()); err != nil {
+				return fail(err)
+			}
+			delete(s.ownerRecords, k.name)
+		}
+	}
+
+	for _, e := range changes.UpdateNew {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		k := mustKey(p, e)
+		wanted := map[string]bool{}
+		for _, t := range e.Targets {
+			wanted[t] = true
+		}
+		ttl := ttlOf(e)
+		for _, r := range s.sets[k] {
+			switch {
+			case !wanted[r.Value]:
+				if err := cs.Delete(r.StringRecordID()); err != nil {
+					return fail(err)
+				}
+			case r.TTL != ttl:
+				if err := cs.Update(r.StringRecordID(), map[string]interface{}{"ttl": ttl}); err != nil {
+					return fail(err)
+				}
+			}
+			delete(wanted, r.Value)
+		}
+		for _, t := range e.Targets {
+			if wanted[t] {
+				create = append(create, p.record(k, t, ttl))
+			}
+		}
+	}
+
+	for _, e := range changes.Create {
+		k := mustKey(p, e)
+		have := map[string]bool{}
+		for _, r := range s.sets[k] {
+			have[r.Value] = true
+		}
+		for _, t := range e.Targets {
+			if !have[t] {
+				create = append(create, p.record(k, t, ttlOf(e)))
+			}
+		}
+		if _, ok := s.ownerRecords[k.name]; !ok {
+			owned := dme.OwnerRecord(k.name, p.OwnerID)
+			create = append(create, owned)
+			s.ownerRecords[k.name] = owned
+		}
+	}
+
+	if len(create) > 0 {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		if _, err := p.Client.CreateRecords(p.DomainID, create); err != nil {
+			return fail(err)
+		}
+	}
+	cs.Commit()
+	return nil
+}
+
+func (p *Provider) record(k setKey, target string, ttl int64) dme.Record {
+	return dme.Record{Name: k.name, Type: k.rrtype, Value: target, TTL: ttl,
+		GtdLocation: string(dme.GTDDefault)}
+}
+
+// mustKey returns the key of an endpoint already checked by ApplyChanges.
+func mustKey(p *Provider, e *Endpoint) setKey {
+	k, _ := p.key(e)
+	return k
+}
+
+func ttlOf(e *Endpoint) int64 {
+	if e.TTL > 0 {
+		return e.TTL
+	}
+	return DefaultTTL
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	dme "github.com/soniah/dnsmadeeasy"
+	"github.com/soniah/dnsmadeeasy/testutil"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newTestProvider(t *testing.T) (*Provider, *testutil.FakeAPI, int64) {
+	api := testutil.NewFakeAPI()
+	domainID := api.AddDomain("example.com")
+	// A record managed by hand.
+	api.AddRecord(domainID, map[string]interface{}{"name": "www", "type": "A", "value": "1.1.1.1", "ttl": 300})
+
+	client, _ := dme.NewClient("akey", "skey")
+	client.URL = api.URL
+	p, err := New(client, "example.com.", "cluster-1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return p, api, domainID
+}
+
+// contents returns the records held as sorted "name type value ttl"
+// strings.
+func contents(api *testutil.FakeAPI, domainID int64) string {
+	var list []string
+	for _, r := range api.Records(domainID) {
+		list = append(list, fmt.Sprintf("%v %v %v %v", r["name"], r["type"], r["value"], r["ttl"]))
+	}
+	sort.Strings(list)
+	return strings.Join(list, "\n")
+}
+
+func TestProviderLifecycle(t *testing.T) {
+	p, api, domainID := newTestProvider(t)
+	defer api.Close()
+	ctx := context.Background()
+
+	app := &Endpoint{DNSName: "app.example.com", RecordType: "A", Targets: []string{"10.0.0.1", "10.0.0.2"}}
+	if err := p.ApplyChanges(ctx, &Changes{Create: []*Endpoint{app}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := `_owner.app TXT "owner=cluster-1" 300
+app A 10.0.0.1 300
+app A 10.0.0.2 300
+www A 1.1.1.1 300`
+	if got := contents(api, domainID); got != expected {
+		t.Fatalf("bad records after create:\n%s", got)
+	}
+
+	// The records are created with one bulk create.
+	var creates []string
+	for _, req := range api.Requests() {
+		if strings.HasPrefix(req, "POST") {
+			creates = append(creates, req)
+		}
+	}
+	if len(creates) != 1 || !strings.HasSuffix(creates[0], "/records/createMulti") {
+		t.Fatalf("bad creates: %v", creates)
+	}
+
+	endpoints, err := p.Records(ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("bad endpoints: %v", endpoints)
+	}
+	www, owned := endpoints[0], endpoints[1]
+	if www.DNSName != "www.example.com" || www.Labels[OwnerLabel] != "" {
+		t.Fatalf("bad unowned endpoint: %#v", www)
+	}
+	if owned.String() != "app.example.com A 10.0.0.1,10.0.0.2" || owned.Labels[OwnerLabel] != "cluster-1" {
+		t.Fatalf("bad owned endpoint: %#v", owned)
+	}
+
+	updated := &Endpoint{DNSName: "app.example.com", RecordType: "A", Targets: []string{"10.0.0.2", "10.0.0.3"}, TTL: 60}
+	if err := p.ApplyChanges(ctx, &Changes{UpdateOld: []*Endpoint{owned}, UpdateNew: []*Endpoint{updated}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected = `_owner.app TXT "owner=cluster-1" 300
+app A 10.0.0.2 60
+app A 10.0.0.3 60
+www A 1.1.1.1 300`
+	if got := contents(api, domainID); got != expected {
+		t.Fatalf("bad records after update:\n%s", got)
+	}
+
+	if err := p.ApplyChanges(ctx, &Changes{Delete: []*Endpoint{updated}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := contents(api, domainID); got != "www A 1.1.1.1 300" {
+		t.Fatalf("bad records after delete:\n%s", got)
+	}
+}
+
+func TestProviderOwnership(t *testing.T) {
+	p, api, domainID := newTestProvider(t)
+	defer api.Close()
+	ctx := context.Background()
+	before := contents(api, domainID)
+
+	// Records managed by hand, or by another owner, are not touched, and
+	// nothing is changed if any change is refused.
+	other := *p
+	other.OwnerID = "cluster-2"
+	if err := other.ApplyChanges(ctx, &Changes{Create: []*Endpoint{
+		{DNSName: "api.example.com", RecordType: "CNAME", Targets: []string{"lb.example.net"}},
+	}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	before = contents(api, domainID)
+	api.Requests()
+
+	www := &Endpoint{DNSName: "www.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}}
+	err := p.ApplyChanges(ctx, &Changes{
+		Create: []*Endpoint{
+			{DNSName: "new.example.com", RecordType: "A", Targets: []string{"10.0.0.9"}},
+			{DNSName: "www.example.com", RecordType: "A", Targets: []string{"10.0.0.1"}},
+			{DNSName: "other.example.org", RecordType: "A", Targets: []string{"10.0.0.1"}},
+		},
+		Delete: []*Endpoint{www,
+			{DNSName: "api.example.com", RecordType: "CNAME", Targets: []string{"lb.example.net"}}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, msg := range []string{
+		"www.example.com already has records and is not owned by cluster-1",
+		"other.example.org is not in zone example.com",
+		"www.example.com A is not owned by cluster-1",
+		"api.example.com CNAME is not owned by cluster-1",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("error %q does not contain %q", err, msg)
+		}
+	}
+	for _, req := range api.Requests() {
+		if !strings.HasPrefix(req, "GET") {
+			t.Fatalf("change made: %s", req)
+		}
+	}
+	if got := contents(api, domainID); got != before {
+		t.Fatalf("records changed:\n%s", got)
+	}
+}
+
+func TestProviderRollsBack(t *testing.T) {
+	p, api, domainID := newTestProvider(t)
+	defer api.Close()
+	ctx := context.Background()
+
+	old := &Endpoint{DNSName: "old.example.com", RecordType: "A", Targets: []string{"10.0.0.1"}}
+	if err := p.ApplyChanges(ctx, &Changes{Create: []*Endpoint{old}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	before := contents(api, domainID)
+
+	// The delete is made, then the bulk create fails, so the delete is
+	// undone.
+	api.FailNext("POST", "/createMulti", 500)
+	err := p.ApplyChanges(ctx, &Changes{
+		Delete: []*Endpoint{old},
+		Create: []*Endpoint{{DNSName: "new.example.com", RecordType: "A", Targets: []string{"10.0.0.2"}}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("bad error: %v", err)
+	}
+	if got := contents(api, domainID); got != before {
+		t.Fatalf("changes not rolled back:\n%s\n%s", got, before)
+	}
+}
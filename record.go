@@ -3,15 +3,21 @@ package dnsmadeeasy
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/imdario/mergo"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 // DataResponse is the response from a GET ie all records for
 // a domainID
 type DataResponse struct {
-	Data []Record `json:"data"`
+	Data         []Record `json:"data"`
+	Page         int      `json:"page"`
+	TotalPages   int      `json:"totalPages"`
+	TotalRecords int      `json:"totalRecords"`
 }
 
 // Record is used to represent a retrieved Record.
@@ -38,6 +44,10 @@ type Record struct {
 	Priority     int64  `json:"priority"`
 	Port         int64  `json:"port"`
 	RedirectType string `json:"redirectType"`
+
+	// IssuerCritical and CaaType are the flags and tag of a CAA record.
+	IssuerCritical int64  `json:"issuerCritical,omitempty"`
+	CaaType        string `json:"caaType,omitempty"`
 }
 
 // StringRecordID returns the record id as a string.
@@ -45,6 +55,22 @@ func (r *Record) StringRecordID() string {
 	return strconv.FormatInt(r.RecordID, 10)
 }
 
+// serverFields are the fields of a Record that are set by the API rather
+// than by callers.
+var serverFields = []string{"id", "source", "sourceId", "failed"}
+
+// Fields returns the record as the fields given to CreateRecord, leaving
+// out the fields set by the API.
+func (r *Record) Fields() map[string]interface{} {
+	data, _ := json.Marshal(r)
+	fields := map[string]interface{}{}
+	json.Unmarshal(data, &fields)
+	for _, name := range serverFields {
+		delete(fields, name)
+	}
+	return fields
+}
+
 // ttl, err := strconv.ParseInt(opts.Ttl, 0, 0)
 
 type requestType int
@@ -66,10 +92,68 @@ func (rt requestType) endpoint(domainID string, recordID string) (result string)
 	return result
 }
 
+// ListRecords gets all the records of a domain.
+func (c *Client) ListRecords(domainID string) ([]Record, error) {
+	return c.cachedRecords(domainID)
+}
+
+// listRecords gets the records of a domain that match the query, which
+// may filter by recordName and type, following every page of results.
+func (c *Client) listRecords(domainID string, query url.Values) ([]Record, error) {
+	var records []Record
+	for page := 0; ; page++ {
+		dataResp, err := c.listRecordsPage(domainID, query, page)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, dataResp.Data...)
+		if page+1 >= dataResp.TotalPages {
+			return records, nil
+		}
+	}
+}
+
+func (c *Client) listRecordsPage(domainID string, query url.Values, page int) (*DataResponse, error) {
+	body := bytes.NewBuffer(nil)
+	path := retrieve.endpoint(domainID, "")
+	if page > 0 {
+		paged := url.Values{}
+		for k, v := range query {
+			paged[k] = v
+		}
+		paged.Set("page", strconv.Itoa(page))
+		query = paged
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := c.NewRequest("GET", path, body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving record: %s", err)
+	}
+
+	dataResp := new(DataResponse)
+	err = decodeBody(resp, dataResp)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding data response: %s", err)
+	}
+	return dataResp, nil
+}
+
 // CRUD - Create, Read, Update, Delete
 
 // CreateRecord creates a DNS record on DNSMadeEasy
 func (c *Client) CreateRecord(domainID string, cr map[string]interface{}) (string, error) {
+	if !c.SkipValidation {
+		if err := ValidateFields(cr); err != nil {
+			return "", err
+		}
+	}
 
 	path := create.endpoint(domainID, "")
 	buf := bytes.NewBuffer(nil)
@@ -83,14 +167,18 @@ func (c *Client) CreateRecord(domainID string, cr map[string]interface{}) (strin
 		return "", fmt.Errorf("Error from NewRequest: %s", err)
 	}
 
-	resp, err := checkResp(c.HTTP.Do(req))
+	resp, err := checkResp(c.do(req))
+	c.invalidate(domainID)
 	if err != nil {
+		sent, _ := recordFromMap(cr)
+		c.journal("create", domainID, "", nil, sent, err)
 		return "", fmt.Errorf("Error creating record: %s", err)
 	}
 
 	record := new(Record)
 
 	err = decodeBody(resp, &record)
+	c.journal("create", domainID, record.StringRecordID(), nil, record, err)
 	if err != nil {
 		return "", fmt.Errorf("Error parsing record response: %s", err)
 	}
@@ -99,81 +187,244 @@ func (c *Client) CreateRecord(domainID string, cr map[string]interface{}) (strin
 	return record.StringRecordID(), nil
 }
 
+// CreateRecords creates several records in one request, and returns them
+// as created, with their IDs. Either all the records are created or none
+// are.
+func (c *Client) CreateRecords(domainID string, records []Record) ([]Record, error) {
+	fields := make([]map[string]interface{}, len(records))
+	for i := range records {
+		if !c.SkipValidation {
+			if err := records[i].Validate(); err != nil {
+				return nil, err
+			}
+		}
+		fields[i] = records[i].Fields()
+	}
+
+	path := create.endpoint(domainID, "") + "createMulti"
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(fields); err != nil {
+		return nil, err
+	}
+
+	req, err := c.NewRequest("POST", path, buf, "")
+	if err != nil {
+		return nil, fmt.Errorf("Error from NewRequest: %s", err)
+	}
+
+	journalFailed := func(err error) {
+		for i := range records {
+			c.journal("create", domainID, "", nil, &records[i], err)
+		}
+	}
+
+	resp, err := checkResp(c.do(req))
+	c.invalidate(domainID)
+	if err != nil {
+		journalFailed(err)
+		return nil, fmt.Errorf("Error creating records: %s", err)
+	}
+
+	var created []Record
+	err = decodeBody(resp, &created)
+	if err != nil {
+		journalFailed(err)
+		return nil, fmt.Errorf("Error parsing records response: %s", err)
+	}
+	for i := range created {
+		c.journal("create", domainID, created[i].StringRecordID(), nil, &created[i], nil)
+	}
+	return created, nil
+}
+
 // ReadRecord gets a record by the ID specified and returns a Record and an
-// error.
+// error. The API cannot fetch a single record by ID, so this lists the
+// domain's records, or uses the listing held by the Client's Cache. If the
+// name and type of the record are known, ReadRecordWithHint makes a
+// narrower request.
 func (c *Client) ReadRecord(domainID string, recordID string) (*Record, error) {
-	body := bytes.NewBuffer(nil)
-	path := retrieve.endpoint(domainID, recordID)
-	req, err := c.NewRequest("GET", path, body, "")
+	records, err := c.cachedRecords(domainID)
 	if err != nil {
 		return nil, err
 	}
+	return findRecordID(records, recordID)
+}
 
-	resp, err := checkResp(c.HTTP.Do(req))
+// ReadRecordWithHint gets a record by the ID specified, listing only the
+// records with the name and type given.
+func (c *Client) ReadRecordWithHint(domainID, recordID, name, rrtype string) (*Record, error) {
+	records, err := c.FindRecords(domainID, name, rrtype)
 	if err != nil {
-		return nil, fmt.Errorf("Error retrieving record: %s", err)
+		return nil, err
 	}
+	return findRecordID(records, recordID)
+}
 
-	dataResp := DataResponse{}
-	err = decodeBody(resp, &dataResp)
-	if err != nil {
-		return nil, fmt.Errorf("Error decoding data response: %s", err)
-	}
-	var result Record
-	var found bool
-	for _, record := range dataResp.Data {
+func findRecordID(records []Record, recordID string) (*Record, error) {
+	for _, record := range records {
 		if record.StringRecordID() == recordID {
-			result = record // not pointer, so data copied
-			found = true
-			break
+			result := record // not pointer, so data copied
+			return &result, nil
 		}
 	}
-
-	if !found {
-		return nil, fmt.Errorf("Unable to find record %s", recordID)
-	}
-	return &result, nil
+	return nil, fmt.Errorf("Unable to find record %s", recordID)
 }
 
+// ErrPreconditionFailed is returned by UpdateRecordIf when the record no
+// longer holds the values expected.
+var ErrPreconditionFailed = errors.New("Record has been changed since it was read")
+
 // UpdateRecord updated a record from the parameters specified and
-// returns an error if it fails.
+// returns an error if it fails. Only the fields given are changed, and
+// they are set even to zero values, such as false or "". Keys are matched
+// to the JSON names of Record without regard to case, and unknown keys are
+// an error. The record is read first as ReadRecord does, listing the whole
+// domain unless the Client has a Cache; UpdateRecordIf reads only the
+// records with the name and type of the record expected.
 func (c *Client) UpdateRecord(domainID string, recordID string, cr map[string]interface{}) (string, error) {
+	return c.UpdateRecordIf(domainID, recordID, nil, cr)
+}
 
-	current, err := c.ReadRecord(domainID, recordID)
-	if err != nil {
+// UpdateRecordIf is UpdateRecord, except that if expected is not nil the
+// update is only made if the record still holds the values in expected.
+// Otherwise ErrPreconditionFailed is returned, so that a change made by
+// someone else since the record was read is not overwritten.
+//
+// The check is best effort. The API has no ETag or If-Match, so the record
+// is read and then written in a separate request, and a change made
+// between the two is still overwritten.
+func (c *Client) UpdateRecordIf(domainID string, recordID string, expected *Record,
+	cr map[string]interface{}) (string, error) {
+
+	if _, err := c.updateRecord(domainID, recordID, expected, cr); err != nil {
 		return "", err
 	}
 
-	err = mergo.Map(current, cr)
+	// The request was successful
+	return recordID, nil
+}
+
+// updateRecord is UpdateRecordIf, returning the record as it was before
+// the update.
+func (c *Client) updateRecord(domainID string, recordID string, expected *Record,
+	cr map[string]interface{}) (*Record, error) {
+
+	var current *Record
+	var err error
+	if expected != nil {
+		current, err = c.ReadRecordWithHint(domainID, recordID, expected.Name, expected.Type)
+	} else {
+		current, err = c.ReadRecord(domainID, recordID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expected != nil && !SameContent(current, expected) {
+		return nil, ErrPreconditionFailed
+	}
+
+	updated, err := applyFields(current, cr)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if err := c.putRecord(domainID, recordID, current, updated); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// applyFields returns a copy of record with the fields given set.
+func applyFields(record *Record, cr map[string]interface{}) (*Record, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	for key, value := range cr {
+		name, ok := recordFieldName(key)
+		if !ok {
+			return nil, fmt.Errorf("Unknown record field %q", key)
+		}
+		for _, server := range serverFields {
+			if name == server {
+				return nil, fmt.Errorf("Record field %q cannot be updated", key)
+			}
+		}
+		merged[name] = value
+	}
+
+	data, err = json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	result := new(Record)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("Error decoding record fields: %s", err)
+	}
+	return result, nil
+}
+
+// recordFieldName returns the JSON name of the Record field matching key
+// without regard to case.
+func recordFieldName(key string) (string, bool) {
+	t := reflect.TypeOf(Record{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// putRecord replaces the record before with the one given.
+func (c *Client) putRecord(domainID string, recordID string, before, record *Record) error {
+	if !c.SkipValidation {
+		if err := record.Validate(); err != nil {
+			return err
+		}
 	}
 
 	buf := bytes.NewBuffer(nil)
 	enc := json.NewEncoder(buf)
-	if err := enc.Encode(current); err != nil {
-		return "", err
+	if err := enc.Encode(record); err != nil {
+		return err
 	}
 
 	path := update.endpoint(domainID, recordID)
 	req, err := c.NewRequest("PUT", path, buf, "")
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	_, err = checkResp(c.HTTP.Do(req))
+	_, err = checkResp(c.do(req))
+	c.invalidate(domainID)
+	c.journal("update", domainID, recordID, before, record, err)
 	if err != nil {
-		return "", fmt.Errorf("Error updating record: %s", err)
+		return fmt.Errorf("Error updating record: %s", err)
 	}
-
-	// The request was successful
-	return recordID, nil
+	return nil
 }
 
 // DeleteRecord destroys a record by the ID specified and
 // returns an error if it fails. If no error is returned,
-// the Record was succesfully destroyed.
+// the Record was succesfully destroyed. If the Client has a Journal, the
+// record is read first as ReadRecord does, for the journal entry.
 func (c *Client) DeleteRecord(domainID string, recordID string) error {
+	// The journal holds the record as it was, so the delete can be
+	// undone.
+	var before *Record
+	if c.Journal != nil {
+		before, _ = c.ReadRecord(domainID, recordID)
+	}
+
 	body := bytes.NewBuffer(nil)
 	path := destroy.endpoint(domainID, recordID)
 	req, err := c.NewRequest("DELETE", path, body, "")
@@ -181,7 +432,9 @@ func (c *Client) DeleteRecord(domainID string, recordID string) error {
 		return err
 	}
 
-	_, err = checkResp(c.HTTP.Do(req))
+	_, err = checkResp(c.do(req))
+	c.invalidate(domainID)
+	c.journal("delete", domainID, recordID, before, nil, err)
 	if err != nil {
 		return fmt.Errorf("Unable to find record %s", recordID)
 	}
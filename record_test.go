@@ -1,9 +1,11 @@
 package dnsmadeeasy
 
 import (
+	"encoding/json"
 	"fmt"
 	. "github.com/motain/gocheck"
 	"github.com/soniah/dnsmadeeasy/testutil"
+	"io/ioutil"
 	"testing"
 )
 
@@ -45,6 +47,7 @@ func (s *S) Test_CreateRecordGood(c *C) {
 	testServer.Response(201, nil, recordCreate)
 	cr := map[string]interface{}{
 		"Name":  "test",
+		"Type":  "A",
 		"Value": "1.1.1.1",
 	}
 	id, err := s.client.CreateRecord("870073", cr)
@@ -57,6 +60,7 @@ func (s *S) Test_CreateRecordBad(c *C) {
 	testServer.Response(404, nil, "")
 	cr := map[string]interface{}{
 		"Name":  "test",
+		"Type":  "A",
 		"Value": "1.1.1.1",
 	}
 	_, err := s.client.CreateRecord("70073", cr)
@@ -81,6 +85,17 @@ func (s *S) Test_ReadRecordBad(c *C) {
 	c.Assert(fmt.Sprintf("%s", err), Equals, "Unable to find record 1003942")
 }
 
+func (s *S) Test_ListRecordsPaged(c *C) {
+	testServer.Response(200, nil, `{"data":[{"id":1,"name":"a"}],"page":0,"totalPages":2}`)
+	testServer.Response(200, nil, `{"data":[{"id":2,"name":"b"}],"page":1,"totalPages":2}`)
+	records, err := s.client.ListRecords("870073")
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+	c.Assert(reqs[0].URL.RawQuery, Equals, "")
+	c.Assert(reqs[1].URL.RawQuery, Equals, "page=1")
+}
+
 func (s *S) Test_UpdateRecordGood(c *C) {
 	testServer.Response(200, nil, recordRead)
 	testServer.Response(200, nil, "")
@@ -105,6 +120,64 @@ func (s *S) Test_UpdateRecordBad(c *C) {
 	c.Assert(fmt.Sprintf("%s", err), Equals, "Unable to find record 100394")
 }
 
+func (s *S) Test_UpdateRecordZeroValues(c *C) {
+	testServer.Response(200, nil, recordReadMonitored)
+	testServer.Response(200, nil, "")
+	cr := map[string]interface{}{
+		"monitor":     false,
+		"Description": "",
+		"TTL":         300,
+	}
+	_, err := s.client.UpdateRecord("870073", "10039429", cr)
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent.Monitor, Equals, false)
+	c.Assert(sent.Description, Equals, "")
+	c.Assert(sent.TTL, Equals, int64(300))
+	c.Assert(sent.Value, Equals, "1.1.1.2")
+}
+
+func (s *S) Test_UpdateRecordUnknownField(c *C) {
+	testServer.Response(200, nil, recordRead)
+	cr := map[string]interface{}{
+		"hostname": "test-update",
+	}
+	_, err := s.client.UpdateRecord("870073", "10039429", cr)
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, `Unknown record field "hostname"`)
+
+	testServer.Response(200, nil, recordRead)
+	cr = map[string]interface{}{
+		"id": 1,
+	}
+	_, err = s.client.UpdateRecord("870073", "10039429", cr)
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, `Record field "id" cannot be updated`)
+}
+
+func (s *S) Test_UpdateRecordIf(c *C) {
+	expected := &Record{Name: "test", Type: "A", Value: "1.1.1.2", TTL: 86400,
+		GtdLocation: "DEFAULT"}
+	testServer.Response(200, nil, recordRead)
+	testServer.Response(200, nil, "")
+	_, err := s.client.UpdateRecordIf("870073", "10039429", expected,
+		map[string]interface{}{"value": "1.1.1.3"})
+	_ = testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+
+	// Someone else has changed the value since it was read.
+	expected.Value = "1.1.1.1"
+	testServer.Response(200, nil, recordRead)
+	_, err = s.client.UpdateRecordIf("870073", "10039429", expected,
+		map[string]interface{}{"value": "1.1.1.3"})
+	_ = testServer.WaitRequest()
+	c.Assert(err, Equals, ErrPreconditionFailed)
+}
+
 func (s *S) Test_DeleteRecordGood(c *C) {
 	testServer.Response(200, nil, "")
 	err := s.client.DeleteRecord("870073", "10039429")
@@ -134,6 +207,27 @@ var recordCreate = `{
   "ttl":86400
 }`
 
+var recordReadMonitored = `{
+  "data":[
+    {
+      "name":"test",
+      "value":"1.1.1.2",
+      "id":10039429,
+      "type":"A",
+      "source":1,
+      "failover":false,
+      "monitor":true,
+      "sourceId":870073,
+      "dynamicDns":false,
+      "failed":false,
+      "gtdLocation":"DEFAULT",
+      "hardLink":false,
+      "description":"web server",
+      "ttl":86400
+    }
+  ]
+}`
+
 var recordRead = `{
   "data":[
     {
@@ -0,0 +1,222 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+)
+
+// OwnerError is returned by a Registry refusing to change records of a name
+// it does not own. Owner is "" if the name has no owner.
+type OwnerError struct {
+	Name    string
+	Owner   string
+	OwnerID string
+}
+
+func (e *OwnerError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "@"
+	}
+	if e.Owner == "" {
+		return fmt.Sprintf("Records of %q have no owner, and are not owned by %q", name, e.OwnerID)
+	}
+	return fmt.Sprintf("Records of %q are owned by %q, not %q", name, e.Owner, e.OwnerID)
+}
+
+// Registry changes the records of a domain on behalf of one owner, so that
+// tools sharing a domain do not change each other's records. The owner of
+// a name is held in its owner record, as made by OwnerRecord, written when
+// the first record of the name is created through a Registry. Unless
+// forced, a Registry only changes or deletes records of names it owns, and
+// only claims names without an owner that have no records, so that records
+// managed by hand are not taken over. This is the rule followed by the
+// provider package.
+type Registry struct {
+	Client   *Client
+	DomainID string
+	OwnerID  string
+}
+
+// NewRegistry returns a Registry for the domain, acting as ownerID.
+func (c *Client) NewRegistry(domainID, ownerID string) *Registry {
+	return &Registry{Client: c, DomainID: domainID, OwnerID: ownerID}
+}
+
+// ownerRecord gets the TXT record holding the owner of a name, or nil if
+// the name has no owner.
+func (reg *Registry) ownerRecord(name string) (*Record, string, error) {
+	records, err := reg.Client.FindRecords(reg.DomainID, OwnerRecordName(name), "TXT")
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range records {
+		if _, owner, ok := RecordOwner(&records[i]); ok {
+			return &records[i], owner, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// Owner returns the owner of the records of a name, or "" if they have
+// none.
+func (reg *Registry) Owner(name string) (string, error) {
+	_, owner, err := reg.ownerRecord(name)
+	return owner, err
+}
+
+// check returns an OwnerError if the name is not owned by the Registry and
+// force is false.
+func (reg *Registry) check(name, owner string, force bool) error {
+	if owner != reg.OwnerID && !force {
+		return &OwnerError{Name: normalizeName(name), Owner: owner, OwnerID: reg.OwnerID}
+	}
+	return nil
+}
+
+// Claim makes the Registry the owner of a name. Unless forced, names owned
+// by someone else, and names without an owner that have records, are not
+// claimed.
+func (reg *Registry) Claim(name string, force bool) error {
+	_, err := reg.claim(name, force)
+	return err
+}
+
+// claim is Claim, returning the ID of the owner record if it created one.
+func (reg *Registry) claim(name string, force bool) (string, error) {
+	record, owner, err := reg.ownerRecord(name)
+	if err != nil {
+		return "", err
+	}
+	if record == nil && !force {
+		records, err := reg.Client.FindRecords(reg.DomainID, name, "")
+		if err != nil {
+			return "", err
+		}
+		if len(records) > 0 {
+			return "", &OwnerError{Name: normalizeName(name), OwnerID: reg.OwnerID}
+		}
+	} else if owner != "" {
+		if err := reg.check(name, owner, force); err != nil {
+			return "", err
+		}
+	}
+
+	owned := OwnerRecord(name, reg.OwnerID)
+	switch {
+	case record == nil:
+		return reg.Client.CreateRecord(reg.DomainID, owned.Fields())
+	case owner != reg.OwnerID:
+		_, err = reg.Client.UpdateRecordIf(reg.DomainID, record.StringRecordID(), record,
+			map[string]interface{}{"value": owned.Value})
+	}
+	return "", err
+}
+
+// unclaim deletes the owner record made by claim for a change that then
+// failed, so that the name is not left owned.
+func (reg *Registry) unclaim(ownerRecordID string, err error) error {
+	if ownerRecordID == "" {
+		return err
+	}
+	if derr := reg.Client.DeleteRecord(reg.DomainID, ownerRecordID); derr != nil {
+		return fmt.Errorf("%s; unable to delete owner record: %s", err, derr)
+	}
+	return err
+}
+
+// nameField returns the name in the fields of a create or update, whose
+// keys are matched without regard to case as CreateRecord and UpdateRecord
+// do.
+func nameField(cr map[string]interface{}) (string, bool) {
+	for key, value := range cr {
+		if field, _ := recordFieldName(key); field == "name" {
+			name, ok := value.(string)
+			return name, ok
+		}
+	}
+	return "", false
+}
+
+// CreateRecord creates a record, claiming its name as Claim does. Unless
+// forced, it fails with an OwnerError if the name cannot be claimed. If the
+// record is not created, a claim made for it is undone.
+func (reg *Registry) CreateRecord(cr map[string]interface{}, force bool) (string, error) {
+	name, _ := nameField(cr)
+	claimed, err := reg.claim(name, force)
+	if err != nil {
+		return "", err
+	}
+	id, err := reg.Client.CreateRecord(reg.DomainID, cr)
+	if err != nil {
+		return "", reg.unclaim(claimed, err)
+	}
+	return id, nil
+}
+
+// UpdateRecord updates a record as Client.UpdateRecord does. Unless forced,
+// it fails with an OwnerError if the name of the record is not owned by the
+// Registry, or if the name it is renamed to cannot be claimed.
+func (reg *Registry) UpdateRecord(recordID string, cr map[string]interface{}, force bool) (string, error) {
+	current, err := reg.Client.ReadRecord(reg.DomainID, recordID)
+	if err != nil {
+		return "", err
+	}
+	owner, err := reg.Owner(current.Name)
+	if err != nil {
+		return "", err
+	}
+	if err := reg.check(current.Name, owner, force); err != nil {
+		return "", err
+	}
+	var claimed string
+	if name, ok := nameField(cr); ok && !SameName(name, current.Name) {
+		if claimed, err = reg.claim(name, force); err != nil {
+			return "", err
+		}
+	}
+
+	// The record is not updated if it is seen to have changed since
+	// ownership was checked, though the check is best effort, as for
+	// UpdateRecordIf.
+	id, err := reg.Client.UpdateRecordIf(reg.DomainID, recordID, current, cr)
+	if err != nil {
+		return "", reg.unclaim(claimed, err)
+	}
+	return id, nil
+}
+
+// DeleteRecord deletes a record. Unless forced, it fails with an
+// OwnerError if the name of the record is not owned by the Registry. When the
+// last record of a name is deleted, the record of its owner is deleted
+// too.
+func (reg *Registry) DeleteRecord(recordID string, force bool) error {
+	current, err := reg.Client.ReadRecord(reg.DomainID, recordID)
+	if err != nil {
+		return err
+	}
+	if IsOwnerRecord(current) && !force {
+		return fmt.Errorf("Record %s holds an owner and is only deleted if forced", recordID)
+	}
+	ownerRecord, owner, err := reg.ownerRecord(current.Name)
+	if err != nil {
+		return err
+	}
+	if err := reg.check(current.Name, owner, force); err != nil {
+		return err
+	}
+	if err := reg.Client.DeleteRecord(reg.DomainID, recordID); err != nil {
+		return err
+	}
+
+	if ownerRecord == nil || IsOwnerRecord(current) {
+		return nil
+	}
+	remaining, err := reg.Client.FindRecords(reg.DomainID, current.Name, "")
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		return reg.Client.DeleteRecord(reg.DomainID, ownerRecord.StringRecordID())
+	}
+	return nil
+}
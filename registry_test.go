@@ -0,0 +1,154 @@
+package dnsmadeeasy
+
+import (
+	"testing"
+)
+
+func TestRegistry_CreateClaimsName(t *testing.T) {
+	api, client, domainID := newFakeDomain(t)
+	defer api.Close()
+	reg := client.NewRegistry(domainID, "deployer")
+
+	if _, err := reg.CreateRecord(map[string]interface{}{"name": "www", "type": "A", "value": "1.1.1.1"}, false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := reg.CreateRecord(map[string]interface{}{"name": "www", "type": "A", "value": "2.2.2.2"}, false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := `_owner.www TXT "owner=deployer", www A 1.1.1.1, www A 2.2.2.2`
+	if got := fakeContents(api, domainID); got != expected {
+		t.Fatalf("bad records: %s", got)
+	}
+	if owner, err := reg.Owner("WWW."); err != nil || owner != "deployer" {
+		t.Fatalf("bad owner: %q %v", owner, err)
+	}
+
+	other := client.NewRegistry(domainID, "certbot")
+	_, err := other.CreateRecord(map[string]interface{}{"name": "www", "type": "A", "value": "3.3.3.3"}, false)
+	if _, ok := err.(*OwnerError); !ok {
+		t.Fatalf("bad error: %v", err)
+	}
+	if err.Error() != `Records of "www" are owned by "deployer", not "certbot"` {
+		t.Fatalf("bad error message: %v", err)
+	}
+	if got := fakeContents(api, domainID); got != expected {
+		t.Fatalf("records changed: %s", got)
+	}
+
+	// Keys are matched without regard to case, as by Client.CreateRecord.
+	_, err = other.CreateRecord(map[string]interface{}{"Name": "www", "Type": "A", "Value": "3.3.3.3"}, false)
+	if _, ok := err.(*OwnerError); !ok {
+		t.Fatalf("bad error: %v", err)
+	}
+	if got := fakeContents(api, domainID); got != expected {
+		t.Fatalf("records changed: %s", got)
+	}
+}
+
+func TestRegistry_RefusesOtherOwners(t *testing.T) {
+	api, client, domainID := newFakeDomain(t,
+		Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300},
+		Record{RecordID: 2, Name: "_owner.www", Type: "TXT", Value: `"owner=deployer"`, TTL: 300},
+		Record{RecordID: 3, Name: "mail", Type: "A", Value: "2.2.2.2", TTL: 300},
+	)
+	defer api.Close()
+	reg := client.NewRegistry(domainID, "certbot")
+	before := fakeContents(api, domainID)
+
+	if _, err := reg.UpdateRecord("1", map[string]interface{}{"value": "9.9.9.9"}, false); err == nil {
+		t.Fatalf("update of a record owned by another was made")
+	}
+	if err := reg.DeleteRecord("1", false); err == nil {
+		t.Fatalf("delete of a record owned by another was made")
+	}
+	if err := reg.DeleteRecord("2", false); err == nil {
+		t.Fatalf("delete of an owner record was made")
+	}
+	// Renaming a record of its own onto a name owned by another is refused.
+	if _, err := reg.UpdateRecord("3", map[string]interface{}{"name": "www"}, false); err == nil {
+		t.Fatalf("rename onto a name owned by another was made")
+	}
+	if got := fakeContents(api, domainID); got != before {
+		t.Fatalf("records changed: %s", got)
+	}
+
+	// Records without an owner are only changed if forced.
+	_, err := reg.UpdateRecord("3", map[string]interface{}{"value": "3.3.3.3"}, false)
+	if err == nil || err.Error() != `Records of "mail" have no owner, and are not owned by "certbot"` {
+		t.Fatalf("bad error: %v", err)
+	}
+	if _, err := reg.UpdateRecord("3", map[string]interface{}{"value": "3.3.3.3"}, true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Forced, the change is made, and the owner record is deleted with the
+	// last record of its name.
+	if _, err := reg.UpdateRecord("1", map[string]interface{}{"value": "9.9.9.9"}, true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := reg.DeleteRecord("1", true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := fakeContents(api, domainID); got != "mail A 3.3.3.3" {
+		t.Fatalf("bad records: %s", got)
+	}
+}
+
+func TestRegistry_ClaimForced(t *testing.T) {
+	api, client, domainID := newFakeDomain(t,
+		Record{RecordID: 1, Name: "", Type: "A", Value: "1.1.1.1", TTL: 300},
+		Record{RecordID: 2, Name: "_owner", Type: "TXT", Value: `"owner=deployer"`, TTL: 300},
+	)
+	defer api.Close()
+	reg := client.NewRegistry(domainID, "certbot")
+
+	if err := reg.Claim("@", false); err == nil {
+		t.Fatalf("claimed a name owned by another")
+	}
+	if err := reg.Claim("@", true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if owner, _ := reg.Owner(""); owner != "certbot" {
+		t.Fatalf("bad owner: %q", owner)
+	}
+	if err := reg.DeleteRecord("1", false); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if got := fakeContents(api, domainID); got != "" {
+		t.Fatalf("bad records: %s", got)
+	}
+}
+
+func TestRegistry_ClaimsOnlyNamesWithoutRecords(t *testing.T) {
+	api, client, domainID := newFakeDomain(t,
+		Record{RecordID: 1, Name: "mail", Type: "A", Value: "2.2.2.2", TTL: 300},
+	)
+	defer api.Close()
+	reg := client.NewRegistry(domainID, "certbot")
+	before := fakeContents(api, domainID)
+
+	// A name holding records managed by hand is not taken over.
+	_, err := reg.CreateRecord(map[string]interface{}{"name": "mail", "type": "A", "value": "3.3.3.3"}, false)
+	if _, ok := err.(*OwnerError); !ok {
+		t.Fatalf("bad error: %v", err)
+	}
+	if got := fakeContents(api, domainID); got != before {
+		t.Fatalf("records changed: %s", got)
+	}
+
+	// A claim made for a record that cannot be created is undone.
+	if _, err := reg.CreateRecord(map[string]interface{}{"name": "www", "type": "A", "value": "not-an-address"}, false); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got := fakeContents(api, domainID); got != before {
+		t.Fatalf("owner record left behind: %s", got)
+	}
+
+	if _, err := reg.CreateRecord(map[string]interface{}{"name": "mail", "type": "A", "value": "3.3.3.3"}, true); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := `_owner.mail TXT "owner=certbot", mail A 2.2.2.2, mail A 3.3.3.3`
+	if got := fakeContents(api, domainID); got != expected {
+		t.Fatalf("bad records: %s", got)
+	}
+}
@@ -0,0 +1,370 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeAPI is an in-memory DNS Made Easy account, served over HTTP for
+// tests. It serves the domain and record endpoints, including bulk record
+// creation. Records are held as their JSON fields, so that the fake does
+// not depend on the client package.
+type FakeAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int64
+	domains  map[int64]map[string]interface{}
+	records  map[int64][]map[string]interface{}
+	requests []string
+	failures []failure
+}
+
+// failure is a request to fail, injected by FailNext.
+type failure struct {
+	method, suffix string
+	status         int
+}
+
+// NewFakeAPI starts a FakeAPI. Close it when done.
+func NewFakeAPI() *FakeAPI {
+	f := &FakeAPI{
+		nextID:  100000,
+		domains: map[int64]map[string]interface{}{},
+		records: map[int64][]map[string]interface{}{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *FakeAPI) newID() int64 {
+	f.nextID++
+	return f.nextID
+}
+
+// AddDomain adds a domain and returns its ID.
+func (f *FakeAPI) AddDomain(name string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.newID()
+	f.domains[id] = map[string]interface{}{"id": id, "name": name, "gtdEnabled": false}
+	return id
+}
+
+// DomainID returns the ID of the domain with the name given.
+func (f *FakeAPI) DomainID(name string) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, d := range f.domains {
+		if d["name"] == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// Domain returns the fields of a domain, decoded into v as they would be
+// read from the API.
+func (f *FakeAPI) Domain(domainID int64, v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.domains[domainID]
+	if !ok {
+		return fmt.Errorf("Domain %d not found", domainID)
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SetDomain sets fields of a domain, such as its assignments.
+func (f *FakeAPI) SetDomain(domainID int64, fields map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k, v := range fields {
+		f.domains[domainID][k] = v
+	}
+}
+
+// AddRecord adds a record to a domain and returns its ID. The record may
+// be a map of fields or a value, such as a record of the client package,
+// that encodes to a JSON object. It gets a new ID unless it has one, and
+// it replaces the record with the same ID if there is one. Fields that are
+// absent default to a source of 1 and the DEFAULT GTD location.
+func (f *FakeAPI) AddRecord(domainID int64, record interface{}) int64 {
+	fields, err := toFields(record)
+	if err != nil {
+		panic(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addRecord(domainID, fields)
+}
+
+// RemoveRecord removes a record from a domain.
+func (f *FakeAPI) RemoveRecord(domainID, recordID int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i := f.recordIndex(domainID, recordID); i >= 0 {
+		records := f.records[domainID]
+		f.records[domainID] = append(records[:i:i], records[i+1:]...)
+	}
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	if fields, ok := v.(map[string]interface{}); ok {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	return fields, json.Unmarshal(data, &fields)
+}
+
+func (f *FakeAPI) addRecord(domainID int64, fields map[string]interface{}) int64 {
+	r := map[string]interface{}{"source": 1, "gtdLocation": "DEFAULT"}
+	for k, v := range fields {
+		r[k] = v
+	}
+	id := recordID(r)
+	if id == 0 {
+		id = f.newID()
+	} else if id > f.nextID {
+		f.nextID = id
+	}
+	r["id"] = id
+	r["sourceId"] = domainID
+	if i := f.recordIndex(domainID, id); i >= 0 {
+		f.records[domainID][i] = r
+	} else {
+		f.records[domainID] = append(f.records[domainID], r)
+	}
+	return id
+}
+
+// Records returns the records of a domain, ordered by ID.
+func (f *FakeAPI) Records(domainID int64) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	records := make([]map[string]interface{}, len(f.records[domainID]))
+	for i, r := range f.records[domainID] {
+		records[i] = map[string]interface{}{}
+		for k, v := range r {
+			records[i][k] = v
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return recordID(records[i]) < recordID(records[j])
+	})
+	return records
+}
+
+// Requests returns the method and path of each request made, such as
+// "POST /dns/managed/100001/records/", and forgets them.
+func (f *FakeAPI) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests
+}
+
+// FailNext makes the next request with the method given, and a path
+// ending in suffix, fail with the status given without making any change.
+func (f *FakeAPI) FailNext(method, suffix string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failure{method, suffix, status})
+}
+
+func recordID(r map[string]interface{}) int64 {
+	switch id := r["id"].(type) {
+	case int64:
+		return id
+	case int:
+		return int64(id)
+	case float64:
+		return int64(id)
+	}
+	return 0
+}
+
+func (f *FakeAPI) serve(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req.Method+" "+req.URL.Path)
+
+	reply := func(status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	fail := func(status int, msg string) {
+		reply(status, map[string]interface{}{"error": []string{msg}})
+	}
+
+	for i, fl := range f.failures {
+		if req.Method == fl.method && strings.HasSuffix(req.URL.Path, fl.suffix) {
+			f.failures = append(f.failures[:i:i], f.failures[i+1:]...)
+			fail(fl.status, "Injected failure")
+			return
+		}
+	}
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/dns/managed"), "/")
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	if len(parts) == 0 {
+		switch req.Method {
+		case "GET":
+			// Listings leave out the assignments of domains.
+			var list []map[string]interface{}
+			for _, d := range f.domains {
+				list = append(list, map[string]interface{}{"id": d["id"], "name": d["name"], "gtdEnabled": d["gtdEnabled"]})
+			}
+			sort.Slice(list, func(i, j int) bool { return recordID(list[i]) < recordID(list[j]) })
+			reply(200, map[string]interface{}{"data": list, "page": 0, "totalPages": 1, "totalRecords": len(list)})
+		case "POST":
+			var d map[string]interface{}
+			if err := json.NewDecoder(req.Body).Decode(&d); err != nil {
+				fail(400, err.Error())
+				return
+			}
+			if _, ok := d["gtdEnabled"]; !ok {
+				d["gtdEnabled"] = false
+			}
+			d["id"] = f.newID()
+			f.domains[recordID(d)] = d
+			reply(201, d)
+		default:
+			fail(405, "Method not allowed")
+		}
+		return
+	}
+
+	domainID, _ := strconv.ParseInt(parts[0], 10, 64)
+	domain, ok := f.domains[domainID]
+	if !ok {
+		fail(404, "Domain not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == "GET":
+		reply(200, domain)
+	case len(parts) == 1 && req.Method == "PUT":
+		var fields map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&fields); err != nil {
+			fail(400, err.Error())
+			return
+		}
+		for k, v := range fields {
+			domain[k] = v
+		}
+		w.WriteHeader(200)
+	case len(parts) == 2 && req.Method == "GET":
+		f.listRecords(w, req, domainID, reply)
+	case len(parts) == 2 && req.Method == "POST":
+		var fields map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&fields); err != nil {
+			fail(400, err.Error())
+			return
+		}
+		delete(fields, "id")
+		id := f.addRecord(domainID, fields)
+		reply(201, f.findRecord(domainID, id))
+	case len(parts) == 3 && parts[2] == "createMulti" && req.Method == "POST":
+		var list []map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&list); err != nil {
+			fail(400, err.Error())
+			return
+		}
+		var created []map[string]interface{}
+		for _, fields := range list {
+			delete(fields, "id")
+			id := f.addRecord(domainID, fields)
+			created = append(created, f.findRecord(domainID, id))
+		}
+		reply(201, created)
+	case len(parts) == 3 && (req.Method == "PUT" || req.Method == "DELETE"):
+		id, _ := strconv.ParseInt(parts[2], 10, 64)
+		i := f.recordIndex(domainID, id)
+		if i < 0 {
+			fail(404, fmt.Sprintf("Record %d not found", id))
+			return
+		}
+		if req.Method == "DELETE" {
+			records := f.records[domainID]
+			f.records[domainID] = append(records[:i:i], records[i+1:]...)
+			w.WriteHeader(200)
+			return
+		}
+		var fields map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&fields); err != nil {
+			fail(400, err.Error())
+			return
+		}
+		if id := recordID(fields); id != 0 && id != recordID(f.records[domainID][i]) {
+			fail(400, "Record ID does not match")
+			return
+		}
+		if _, ok := fields["source"]; !ok {
+			fields["source"] = f.records[domainID][i]["source"]
+		}
+		fields["id"] = recordID(f.records[domainID][i])
+		fields["sourceId"] = domainID
+		f.records[domainID][i] = fields
+		w.WriteHeader(200)
+	default:
+		fail(405, "Method not allowed")
+	}
+}
+
+// listRecords answers a listing, filtered by the recordName and type
+// parameters as the API does.
+func (f *FakeAPI) listRecords(w http.ResponseWriter, req *http.Request, domainID int64,
+	reply func(int, interface{})) {
+
+	name := req.URL.Query().Get("recordName")
+	rrtype := req.URL.Query().Get("type")
+	list := []map[string]interface{}{}
+	for _, r := range f.records[domainID] {
+		if name != "" && !strings.EqualFold(fmt.Sprint(r["name"]), name) {
+			continue
+		}
+		if rrtype != "" && !strings.EqualFold(fmt.Sprint(r["type"]), rrtype) {
+			continue
+		}
+		list = append(list, r)
+	}
+	reply(200, map[string]interface{}{"data": list, "page": 0, "totalPages": 1, "totalRecords": len(list)})
+}
+
+func (f *FakeAPI) recordIndex(domainID, id int64) int {
+	for i, r := range f.records[domainID] {
+		if recordID(r) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *FakeAPI) findRecord(domainID, id int64) map[string]interface{} {
+	if i := f.recordIndex(domainID, id); i >= 0 {
+		return f.records[domainID][i]
+	}
+	return nil
+}
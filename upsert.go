@@ -0,0 +1,157 @@
+package dnsmadeeasy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UpsertAction is what UpsertRecord did.
+type UpsertAction int
+
+// Actions taken by UpsertRecord.
+const (
+	UpsertUnchanged UpsertAction = iota
+	UpsertCreated
+	UpsertUpdated
+)
+
+func (a UpsertAction) String() string {
+	switch a {
+	case UpsertCreated:
+		return "created"
+	case UpsertUpdated:
+		return "updated"
+	}
+	return "unchanged"
+}
+
+// MultipleMatch is how UpsertRecord treats more than one record with the
+// name and type of the record given.
+type MultipleMatch int
+
+const (
+	// MatchError fails the upsert.
+	MatchError MultipleMatch = iota
+
+	// MatchFirst upserts over the first record found.
+	MatchFirst
+
+	// MatchValue treats the records as a set, such as a round robin set
+	// of A records. The record with the same value is upserted over, and
+	// if there is none, the record is added to the set.
+	MatchValue
+)
+
+// UpsertOptions configure UpsertRecordWithOptions.
+type UpsertOptions struct {
+	OnMultiple MultipleMatch
+}
+
+// UpsertResult reports what UpsertRecord did, and the ID of the record
+// created, updated or left unchanged.
+type UpsertResult struct {
+	Action   UpsertAction
+	RecordID string
+}
+
+// UpsertRecord makes sure the domain has a record with the name and type
+// of the one given, holding the same values. It creates the record if
+// there is none, updates it if it differs, and otherwise does nothing. Only
+// the fields set in r, those not holding zero values, are compared and
+// updated. It fails if more than one record has the name and type.
+func (c *Client) UpsertRecord(domainID string, r Record) (*UpsertResult, error) {
+	return c.UpsertRecordWithOptions(domainID, r, UpsertOptions{})
+}
+
+// UpsertRecordWithOptions is UpsertRecord, with the handling of multiple
+// matching records set by opts.
+func (c *Client) UpsertRecordWithOptions(domainID string, r Record, opts UpsertOptions) (*UpsertResult, error) {
+	matches, err := c.FindRecords(domainID, r.Name, r.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OnMultiple == MatchValue {
+		var sameValue []Record
+		for _, m := range matches {
+			if m.Value == r.Value {
+				sameValue = append(sameValue, m)
+			}
+		}
+		matches = sameValue
+	}
+
+	switch {
+	case len(matches) == 0:
+		id, err := c.CreateRecord(domainID, r.Fields())
+		if err != nil {
+			return nil, err
+		}
+		return &UpsertResult{Action: UpsertCreated, RecordID: id}, nil
+	case len(matches) > 1 && opts.OnMultiple == MatchError:
+		return nil, fmt.Errorf("Found %d %s records %q, expected at most one",
+			len(matches), r.Type, r.Name)
+	}
+
+	// Fields left unset in r, such as monitoring or the TTL, keep their
+	// current values rather than being reset.
+	current := matches[0]
+	result := &UpsertResult{RecordID: current.StringRecordID()}
+	// The name and type were matched without regard to how they are
+	// written, so the current spelling is kept rather than sent as a
+	// rename.
+	fields := setFields(&r)
+	if SameName(r.Name, current.Name) {
+		delete(fields, "name")
+	}
+	if strings.EqualFold(r.Type, current.Type) {
+		delete(fields, "type")
+	}
+	desired, err := applyFields(&current, fields)
+	if err != nil {
+		return nil, err
+	}
+	if SameContent(&current, desired) {
+		result.Action = UpsertUnchanged
+		return result, nil
+	}
+
+	if err := c.putRecord(domainID, result.RecordID, &current, desired); err != nil {
+		return nil, err
+	}
+	result.Action = UpsertUpdated
+	return result, nil
+}
+
+// setFields returns the fields of a record that are set, leaving out those
+// holding zero values.
+func setFields(r *Record) map[string]interface{} {
+	fields := r.Fields()
+	for name, value := range fields {
+		switch value {
+		case nil, false, "", float64(0):
+			delete(fields, name)
+		}
+	}
+	return fields
+}
+
+// SameContent reports whether two records hold the same values, ignoring
+// the fields set by the API.
+func SameContent(a, b *Record) bool {
+	return reflect.DeepEqual(ContentFields(a), ContentFields(b))
+}
+
+// ContentFields returns the fields of a record as Fields does, in a
+// canonical form for comparison: the name as the API writes it, the type
+// in upper case, and the default GTD location where none is set.
+func ContentFields(r *Record) map[string]interface{} {
+	fields := r.Fields()
+	fields["name"] = normalizeName(r.Name)
+	fields["type"] = strings.ToUpper(r.Type)
+	if r.GtdLocation == "" {
+		fields["gtdLocation"] = string(GTDDefault)
+	}
+	return fields
+}
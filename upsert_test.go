@@ -0,0 +1,139 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	. "github.com/motain/gocheck"
+	"io/ioutil"
+)
+
+func (s *S) Test_UpsertRecordCreated(c *C) {
+	testServer.Response(200, nil, `{"data":[]}`)
+	testServer.Response(201, nil, recordCreate)
+	result, err := s.client.UpsertRecord("870073",
+		Record{Name: "test", Type: "A", Value: "1.1.1.1", TTL: 86400})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertCreated)
+	c.Assert(result.RecordID, Equals, "10022989")
+	c.Assert(reqs[1].Method, Equals, "POST")
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := map[string]interface{}{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	_, ok := sent["id"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) Test_UpsertRecordUnchanged(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	result, err := s.client.UpsertRecord("870073",
+		Record{Name: "@", Type: "MX", Value: "mail", MXLevel: 10, TTL: 86400})
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUnchanged)
+	c.Assert(result.RecordID, Equals, "10039430")
+}
+
+func (s *S) Test_UpsertRecordUpdated(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	testServer.Response(200, nil, "")
+	result, err := s.client.UpsertRecord("870073",
+		Record{Name: "www", Type: "CNAME", Value: "web", TTL: 300})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUpdated)
+	c.Assert(reqs[1].Method, Equals, "PUT")
+	c.Assert(reqs[1].URL.Path, Equals, "/dns/managed/870073/records/10039431/")
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent.RecordID, Equals, int64(10039431))
+	c.Assert(sent.Value, Equals, "web")
+	c.Assert(sent.TTL, Equals, int64(300))
+}
+
+func (s *S) Test_UpsertRecordKeepsName(c *C) {
+	// A name and type written differently are not sent as a rename.
+	testServer.Response(200, nil, recordsFind)
+	testServer.Response(200, nil, "")
+	result, err := s.client.UpsertRecord("870073",
+		Record{Name: "WWW.", Type: "cname", Value: "web"})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUpdated)
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent.Name, Equals, "www")
+	c.Assert(sent.Type, Equals, "CNAME")
+	c.Assert(sent.Value, Equals, "web")
+}
+
+func (s *S) Test_UpsertRecordMultiple(c *C) {
+	testServer.Response(200, nil, recordsFind)
+	_, err := s.client.UpsertRecord("870073",
+		Record{Name: "test", Type: "A", Value: "1.1.1.3", TTL: 86400})
+	_ = testServer.WaitRequest()
+	c.Assert(err, ErrorMatches, `Found 2 A records "test", expected at most one`)
+
+	// As a set, a new value is added alongside the others.
+	testServer.Response(200, nil, recordsFind)
+	testServer.Response(201, nil, recordCreate)
+	result, err := s.client.UpsertRecordWithOptions("870073",
+		Record{Name: "test", Type: "A", Value: "1.1.1.3", TTL: 86400},
+		UpsertOptions{OnMultiple: MatchValue})
+	_ = testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertCreated)
+
+	// And an existing value is left alone.
+	testServer.Response(200, nil, recordsFind)
+	result, err = s.client.UpsertRecordWithOptions("870073",
+		Record{Name: "test", Type: "A", Value: "1.1.1.2", TTL: 86400},
+		UpsertOptions{OnMultiple: MatchValue})
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUnchanged)
+	c.Assert(result.RecordID, Equals, "10039429")
+}
+
+var monitoredRecord = `{"data":[{"id":10039432,"name":"www","type":"A","value":"1.1.1.1",
+	"ttl":300,"gtdLocation":"DEFAULT","source":1,"sourceId":870073,
+	"monitor":true,"failover":true,"dynamicDns":false,"hardLink":false}],"totalPages":1}`
+
+func (s *S) Test_UpsertRecordKeepsUnsetFields(c *C) {
+	// Monitoring is not given, so the record is unchanged.
+	testServer.Response(200, nil, monitoredRecord)
+	result, err := s.client.UpsertRecord("870073",
+		Record{Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300})
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUnchanged)
+
+	// Only the value is updated, keeping the TTL and failover.
+	testServer.Response(200, nil, monitoredRecord)
+	testServer.Response(200, nil, "")
+	result, err = s.client.UpsertRecord("870073",
+		Record{Name: "www", Type: "A", Value: "2.2.2.2"})
+	reqs := testServer.WaitRequests(2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Action, Equals, UpsertUpdated)
+
+	body, _ := ioutil.ReadAll(reqs[1].Body)
+	sent := Record{}
+	c.Assert(json.Unmarshal(body, &sent), IsNil)
+	c.Assert(sent.Value, Equals, "2.2.2.2")
+	c.Assert(sent.TTL, Equals, int64(300))
+	c.Assert(sent.Monitor, Equals, true)
+	c.Assert(sent.Failover, Equals, true)
+}
+
+func (s *S) Test_SameContent(c *C) {
+	a := Record{Name: "WWW.", Type: "a", Value: "1.1.1.1", TTL: 300}
+	b := Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300, GtdLocation: "DEFAULT"}
+	c.Assert(SameContent(&a, &b), Equals, true)
+	b.TTL = 60
+	c.Assert(SameContent(&a, &b), Equals, false)
+}
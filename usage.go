@@ -0,0 +1,164 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// UsageResponse is the response from a GET of the query usage API.
+type UsageResponse struct {
+	Data []QueryUsage `json:"data"`
+}
+
+// QueryUsage is the number of queries answered for a domain over one
+// period. Day is zero for monthly totals.
+type QueryUsage struct {
+	Year           int64  `json:"year"`
+	Month          int64  `json:"month"`
+	Day            int64  `json:"day"`
+	DomainID       int64  `json:"domainId"`
+	DomainName     string `json:"domainName"`
+	PrimaryCount   int64  `json:"primaryCount"`
+	SecondaryCount int64  `json:"secondaryCount"`
+}
+
+// Total returns the sum of primary and secondary queries.
+func (u *QueryUsage) Total() int64 {
+	return u.PrimaryCount + u.SecondaryCount
+}
+
+// QueryUsage gets the query usage of every domain of the account, over
+// all periods available.
+func (c *Client) QueryUsage() ([]QueryUsage, error) {
+	return c.queryUsage("/usageApi/queriesApi/")
+}
+
+// QueryUsageByMonth gets the query usage of every domain of the account for
+// the month specified.
+func (c *Client) QueryUsageByMonth(year, month int) ([]QueryUsage, error) {
+	return c.queryUsage(fmt.Sprintf("/usageApi/queriesApi/%d/%d", year, month))
+}
+
+// QueryUsageByDomain gets the query usage of one domain for the month
+// specified.
+func (c *Client) QueryUsageByDomain(year, month int, domainID string) ([]QueryUsage, error) {
+	return c.queryUsage(fmt.Sprintf("/usageApi/queriesApi/%d/%d/managed/%s",
+		year, month, domainID))
+}
+
+// queryUsage gets a usage time series, ordered by date then domain.
+func (c *Client) queryUsage(path string) ([]QueryUsage, error) {
+	body := bytes.NewBuffer(nil)
+	req, err := c.NewRequest("GET", path, body, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := checkResp(c.do(req))
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving usage: %s", err)
+	}
+
+	usageResp := UsageResponse{}
+	err = decodeBody(resp, &usageResp)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding usage response: %s", err)
+	}
+
+	usage := usageResp.Data
+	sort.SliceStable(usage, func(i, j int) bool {
+		a, b := usage[i], usage[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.DomainID < b.DomainID
+	})
+	return usage, nil
+}
+
+// AggregateUsage sums a usage time series per domain, per month. The
+// result is ordered like the input.
+func AggregateUsage(usage []QueryUsage) []QueryUsage {
+	type key struct {
+		year, month, domainID int64
+	}
+	var result []QueryUsage
+	index := map[key]int{}
+	for _, u := range usage {
+		k := key{u.Year, u.Month, u.DomainID}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(result)
+			result = append(result, QueryUsage{
+				Year:       u.Year,
+				Month:      u.Month,
+				DomainID:   u.DomainID,
+				DomainName: u.DomainName,
+			})
+			i = index[k]
+		}
+		result[i].PrimaryCount += u.PrimaryCount
+		result[i].SecondaryCount += u.SecondaryCount
+	}
+	return result
+}
+
+// WriteUsageCSV writes usage as CSV, one row per domain per month, followed
+// by a row holding the totals across all domains.
+func WriteUsageCSV(w io.Writer, usage []QueryUsage) error {
+	cw := csv.NewWriter(w)
+	header := []string{"year", "month", "domain_id", "domain_name",
+		"primary", "secondary", "total"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	var total QueryUsage
+	for _, u := range AggregateUsage(usage) {
+		row := []string{
+			strconv.FormatInt(u.Year, 10),
+			strconv.FormatInt(u.Month, 10),
+			strconv.FormatInt(u.DomainID, 10),
+			u.DomainName,
+			strconv.FormatInt(u.PrimaryCount, 10),
+			strconv.FormatInt(u.SecondaryCount, 10),
+			strconv.FormatInt(u.Total(), 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		total.PrimaryCount += u.PrimaryCount
+		total.SecondaryCount += u.SecondaryCount
+	}
+
+	row := []string{"", "", "", "TOTAL",
+		strconv.FormatInt(total.PrimaryCount, 10),
+		strconv.FormatInt(total.SecondaryCount, 10),
+		strconv.FormatInt(total.Total(), 10),
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// UsageReportCSV writes a CSV report of the query usage of every domain of
+// the account for the month specified.
+func (c *Client) UsageReportCSV(w io.Writer, year, month int) error {
+	usage, err := c.QueryUsageByMonth(year, month)
+	if err != nil {
+		return err
+	}
+	return WriteUsageCSV(w, usage)
+}
@@ -0,0 +1,47 @@
+package dnsmadeeasy
+
+import (
+	"bytes"
+	. "github.com/motain/gocheck"
+)
+
+func (s *S) Test_QueryUsageByDomain(c *C) {
+	testServer.Response(200, nil, usageRead)
+	usage, err := s.client.QueryUsageByDomain(2016, 3, "870073")
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/usageApi/queriesApi/2016/3/managed/870073")
+	c.Assert(usage, HasLen, 3)
+	c.Assert(usage[0].Day, Equals, int64(1))
+	c.Assert(usage[0].Total(), Equals, int64(150))
+}
+
+func (s *S) Test_UsageReportCSV(c *C) {
+	testServer.Response(200, nil, usageRead)
+	buf := bytes.NewBuffer(nil)
+	err := s.client.UsageReportCSV(buf, 2016, 3)
+	req := testServer.WaitRequest()
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/usageApi/queriesApi/2016/3")
+	c.Assert(buf.String(), Equals, `year,month,domain_id,domain_name,primary,secondary,total
+2016,3,870073,example.com,300,50,350
+2016,3,870074,example.net,10,0,10
+,,,TOTAL,310,50,360
+`)
+}
+
+func (s *S) Test_QueryUsageBad(c *C) {
+	testServer.Response(500, nil, "")
+	usage, err := s.client.QueryUsage()
+	_ = testServer.WaitRequest()
+	c.Assert(err, NotNil)
+	c.Assert(usage, IsNil)
+}
+
+var usageRead = `{
+  "data":[
+    {"year":2016,"month":3,"day":2,"domainId":870073,"domainName":"example.com","primaryCount":200,"secondaryCount":0},
+    {"year":2016,"month":3,"day":1,"domainId":870074,"domainName":"example.net","primaryCount":10,"secondaryCount":0},
+    {"year":2016,"month":3,"day":1,"domainId":870073,"domainName":"example.com","primaryCount":100,"secondaryCount":50}
+  ]
+}`
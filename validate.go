@@ -0,0 +1,238 @@
+package dnsmadeeasy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Bounds of a record's TTL. A TTL of zero is left for the API to default.
+const (
+	MinTTL int64 = 30
+	MaxTTL int64 = 2147483647
+)
+
+// requiredFields are the fields, other than name, type and value, that
+// must be given when creating a record of each type.
+var requiredFields = map[string][]string{
+	"MX":  {"mxLevel"},
+	"SRV": {"priority", "weight", "port"},
+}
+
+// recordFromMap decodes the fields given to CreateRecord or UpdateRecord
+// into a Record. Keys are matched to the JSON names of Record without
+// regard to case.
+func recordFromMap(cr map[string]interface{}) (*Record, error) {
+	data, err := json.Marshal(cr)
+	if err != nil {
+		return nil, err
+	}
+	record := new(Record)
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, fmt.Errorf("Error decoding record fields: %s", err)
+	}
+	return record, nil
+}
+
+// ValidateFields checks that the fields given to CreateRecord describe a
+// valid record, including that fields required by the record's type are
+// present.
+func ValidateFields(cr map[string]interface{}) error {
+	record, err := recordFromMap(cr)
+	if err != nil {
+		return err
+	}
+	for _, field := range requiredFields[strings.ToUpper(record.Type)] {
+		found := false
+		for k := range cr {
+			if strings.EqualFold(k, field) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Invalid %s record %q: %s is required",
+				record.Type, record.Name, field)
+		}
+	}
+	return record.Validate()
+}
+
+// Validate checks the record follows the rules of its type, so that
+// mistakes are caught before a request is sent.
+func (r *Record) Validate() error {
+	if err := r.validate(); err != nil {
+		return fmt.Errorf("Invalid %s record %q: %s", r.Type, r.Name, err)
+	}
+	return nil
+}
+
+func (r *Record) validate() error {
+	if r.TTL < 0 || (r.TTL > 0 && r.TTL < MinTTL) || r.TTL > MaxTTL {
+		return fmt.Errorf("ttl %d is out of range %d to %d", r.TTL, MinTTL, MaxTTL)
+	}
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if r.GtdLocation != "" && !GTDLocation(r.GtdLocation).Valid() {
+		return fmt.Errorf("gtdLocation %q is not a known location", r.GtdLocation)
+	}
+
+	// Types are matched without regard to case, as the API does.
+	rrtype := strings.ToUpper(r.Type)
+	switch rrtype {
+	case "A":
+		ip := net.ParseIP(r.Value)
+		if ip == nil || ip.To4() == nil || strings.Contains(r.Value, ":") {
+			return fmt.Errorf("value %q is not an IPv4 address", r.Value)
+		}
+	case "AAAA":
+		ip := net.ParseIP(r.Value)
+		if ip == nil || !strings.Contains(r.Value, ":") {
+			return fmt.Errorf("value %q is not an IPv6 address", r.Value)
+		}
+	case "CNAME":
+		if isApex(r.Name) {
+			return fmt.Errorf("a CNAME cannot be created at the apex")
+		}
+		return validateHostname(r.Value)
+	case "ANAME", "NS", "PTR":
+		return validateHostname(r.Value)
+	case "MX":
+		if err := validateUint16("mxLevel", r.MXLevel); err != nil {
+			return err
+		}
+		return validateHostname(r.Value)
+	case "TXT", "SPF":
+		return validateText(r.Value)
+	case "SRV":
+		labels := strings.Split(r.Name, ".")
+		if len(labels) < 2 || !strings.HasPrefix(labels[0], "_") ||
+			!strings.HasPrefix(labels[1], "_") {
+			return fmt.Errorf("name must be of the form _service._proto")
+		}
+		if err := validateUint16("priority", r.Priority); err != nil {
+			return err
+		}
+		if err := validateUint16("weight", r.Weight); err != nil {
+			return err
+		}
+		if r.Port < 1 || r.Port > 65535 {
+			return fmt.Errorf("port %d is out of range 1 to 65535", r.Port)
+		}
+		return validateHostname(r.Value)
+	case "CAA":
+		upper := *r
+		upper.Type = rrtype
+		caa, err := CAAFromRecord(&upper)
+		if err != nil {
+			return err
+		}
+		return caa.Validate()
+	case "HTTPRED":
+		upper := *r
+		upper.Type = rrtype
+		h, err := HTTPRedirectFromRecord(&upper)
+		if err != nil {
+			return err
+		}
+		return h.Validate()
+	case "":
+		return fmt.Errorf("type is required")
+	default:
+		return fmt.Errorf("unknown type")
+	}
+	return nil
+}
+
+// isApex reports whether a record name refers to the domain itself.
+func isApex(name string) bool {
+	return name == "" || name == "@"
+}
+
+// validateName checks a record name, which is relative to its domain. A
+// wildcard is allowed as the first label.
+func validateName(name string) error {
+	if isApex(name) {
+		return nil
+	}
+	labels := strings.Split(name, ".")
+	if labels[0] == "*" {
+		if len(labels) == 1 {
+			return nil
+		}
+		labels = labels[1:]
+	}
+	return validateLabels(name, labels)
+}
+
+// validateHostname checks a value that names a host. It may be relative
+// to the domain or, with a trailing dot, fully qualified.
+func validateHostname(value string) error {
+	if value == "" {
+		return fmt.Errorf("value is required")
+	}
+	if value == "@" {
+		return nil
+	}
+	return validateLabels(value, strings.Split(strings.TrimSuffix(value, "."), "."))
+}
+
+func validateLabels(name string, labels []string) error {
+	if len(name) > 253 {
+		return fmt.Errorf("%q is longer than 253 characters", name)
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("%q has a label that is empty or longer "+
+				"than 63 characters", name)
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' ||
+				ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+				return fmt.Errorf("%q contains the invalid character %q", name, ch)
+			}
+		}
+	}
+	return nil
+}
+
+// validateText checks a TXT or SPF value. Values longer than 255
+// characters must be split into quoted strings, each of which is at most
+// 255 characters.
+func validateText(value string) error {
+	if value == "" {
+		return fmt.Errorf("value is required")
+	}
+	if !strings.HasPrefix(value, `"`) {
+		if len(value) > 255 {
+			return fmt.Errorf("value is longer than 255 characters and " +
+				"must be split into quoted strings")
+		}
+		return nil
+	}
+
+	// The parts of the split value alternate between the contents of a
+	// quoted string and the text between two quoted strings.
+	parts := strings.Split(value, `"`)
+	if len(parts)%2 == 0 {
+		return fmt.Errorf("value has an unterminated quoted string")
+	}
+	for i, part := range parts {
+		if i%2 == 1 && len(part) > 255 {
+			return fmt.Errorf("value has a quoted string longer than 255 characters")
+		}
+		if i%2 == 0 && strings.TrimSpace(part) != "" {
+			return fmt.Errorf("value has text outside of quoted strings")
+		}
+	}
+	return nil
+}
+
+func validateUint16(field string, v int64) error {
+	if v < 0 || v > 65535 {
+		return fmt.Errorf("%s %d is out of range 0 to 65535", field, v)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package dnsmadeeasy
+
+import (
+	. "github.com/motain/gocheck"
+	"strings"
+)
+
+func (s *S) Test_RecordValidateGood(c *C) {
+	for _, r := range []Record{
+		{Name: "www", Type: "A", Value: "1.1.1.1", TTL: 86400},
+		{Name: "", Type: "A", Value: "1.1.1.1"},
+		{Name: "*.dev", Type: "A", Value: "1.1.1.1"},
+		{Name: "v6", Type: "AAAA", Value: "2001:db8::1"},
+		{Name: "www", Type: "CNAME", Value: "web.example.net."},
+		{Name: "@", Type: "ANAME", Value: "lb.example.net."},
+		{Name: "", Type: "MX", Value: "mail", MXLevel: 10},
+		{Name: "", Type: "TXT", Value: "v=spf1 -all"},
+		{Name: "", Type: "TXT", Value: `"` + strings.Repeat("a", 255) + `" "b"`},
+		{Name: "_sip._tcp", Type: "SRV", Value: "sip", Priority: 10, Weight: 5, Port: 5060},
+		{Name: "sub", Type: "NS", Value: "ns1.example.net."},
+		{Name: "1", Type: "PTR", Value: "host.example.com."},
+		{Name: "", Type: "CAA", CaaType: "issue", Value: `"letsencrypt.org"`},
+		{Name: "go", Type: "HTTPRED", Value: "https://example.net/", RedirectType: RedirectPermanent},
+		{Name: "www", Type: "a", Value: "1.1.1.1", GtdLocation: "EUROPE"},
+		{Name: "", Type: "caa", CaaType: "issue", Value: `"letsencrypt.org"`},
+	} {
+		c.Check(r.Validate(), IsNil, Commentf("%+v", r))
+	}
+}
+
+func (s *S) Test_RecordValidateBad(c *C) {
+	for _, t := range []struct {
+		r   Record
+		err string
+	}{
+		{Record{Name: "www", Value: "1.1.1.1"}, `Invalid  record "www": type is required`},
+		{Record{Name: "www", Type: "MD", Value: "1.1.1.1"}, `.*unknown type`},
+		{Record{Name: "www", Type: "A", Value: "1.1.1.1", TTL: 10}, `.*ttl 10 is out of range.*`},
+		{Record{Name: "www", Type: "A", Value: "2001:db8::1"}, `.*not an IPv4 address`},
+		{Record{Name: "www", Type: "AAAA", Value: "1.1.1.1"}, `.*not an IPv6 address`},
+		{Record{Name: "www", Type: "AAAA", Value: "2001:db8::g"}, `.*not an IPv6 address`},
+		{Record{Name: "", Type: "CNAME", Value: "web"}, `.*cannot be created at the apex`},
+		{Record{Name: "a..b", Type: "A", Value: "1.1.1.1"}, `.*label that is empty.*`},
+		{Record{Name: strings.Repeat("a", 64), Type: "A", Value: "1.1.1.1"}, `.*longer than 63.*`},
+		{Record{Name: "w w", Type: "A", Value: "1.1.1.1"}, `.*invalid character.*`},
+		{Record{Name: "", Type: "MX", Value: "mail", MXLevel: 70000}, `.*mxLevel 70000.*`},
+		{Record{Name: "", Type: "TXT", Value: strings.Repeat("a", 256)}, `.*must be split.*`},
+		{Record{Name: "", Type: "TXT", Value: `"abc`}, `.*unterminated.*`},
+		{Record{Name: "sip", Type: "SRV", Value: "sip", Port: 5060}, `.*_service._proto`},
+		{Record{Name: "_sip._tcp", Type: "SRV", Value: "sip"}, `.*port 0.*`},
+		{Record{Name: "", Type: "CAA", CaaType: "issues", Value: "ca.example"}, `.*Invalid CAA tag "issues"`},
+		{Record{Name: "go", Type: "HTTPRED", Value: "example.net", RedirectType: RedirectPermanent}, `.*Invalid redirect URL.*`},
+		{Record{Name: "www", Type: "a", Value: "2001:db8::1"}, `.*not an IPv4 address`},
+		{Record{Name: "www", Type: "A", Value: "1.1.1.1", GtdLocation: "MARS"}, `.*gtdLocation "MARS" is not a known location`},
+	} {
+		c.Check(t.r.Validate(), ErrorMatches, t.err, Commentf("%+v", t.r))
+	}
+}
+
+func (s *S) Test_ValidateFieldsRequired(c *C) {
+	err := ValidateFields(map[string]interface{}{
+		"name":  "",
+		"type":  "MX",
+		"value": "mail",
+	})
+	c.Assert(err, ErrorMatches, `Invalid MX record "": mxLevel is required`)
+
+	err = ValidateFields(map[string]interface{}{
+		"name":  "",
+		"type":  "mx",
+		"value": "mail",
+	})
+	c.Assert(err, ErrorMatches, `Invalid mx record "": mxLevel is required`)
+
+	err = ValidateFields(map[string]interface{}{
+		"name":    "",
+		"type":    "MX",
+		"value":   "mail",
+		"MXLevel": 0,
+	})
+	c.Assert(err, IsNil)
+
+	err = ValidateFields(map[string]interface{}{
+		"name":     "_sip._tcp",
+		"type":     "SRV",
+		"value":    "sip",
+		"priority": 10,
+		"port":     5060,
+	})
+	c.Assert(err, ErrorMatches, `.*weight is required`)
+}
+
+func (s *S) Test_CreateRecordInvalid(c *C) {
+	cr := map[string]interface{}{
+		"name":  "test",
+		"type":  "A",
+		"value": "1.1.1",
+	}
+	_, err := s.client.CreateRecord("870073", cr)
+	c.Assert(err, ErrorMatches, `.*not an IPv4 address`)
+}
+
+func (s *S) Test_CreateRecordSkipValidation(c *C) {
+	testServer.Response(201, nil, recordCreate)
+	client := *s.client
+	client.SkipValidation = true
+	cr := map[string]interface{}{
+		"name":  "test",
+		"value": "1.1.1",
+	}
+	_, err := client.CreateRecord("870073", cr)
+	_ = testServer.WaitRequest()
+	c.Assert(err, IsNil)
+}
@@ -0,0 +1,178 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EventType is the kind of a change seen by Watch.
+type EventType int
+
+// Types of Event.
+const (
+	EventAdded EventType = iota
+	EventModified
+	EventRemoved
+
+	// EventError reports a failed poll. Watching carries on.
+	EventError
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventModified:
+		return "modified"
+	case EventRemoved:
+		return "removed"
+	case EventError:
+		return "error"
+	}
+	return "added"
+}
+
+// Event is a change to a record of a watched domain.
+type Event struct {
+	Type EventType
+
+	// Record is the record as added or modified, or as it was when
+	// removed.
+	Record Record
+
+	// Previous is the record before it was modified.
+	Previous *Record
+
+	// Err is the error of an EventError.
+	Err error
+}
+
+// recordHash is a digest of every field of a record, so that a change to
+// any of them is seen.
+type recordHash [sha256.Size]byte
+
+func hashRecord(r *Record) recordHash {
+	data, _ := json.Marshal(r)
+	return sha256.Sum256(data)
+}
+
+// snapshot is a listing of a domain's records, with a digest of the whole
+// listing so that an unchanged listing is skipped cheaply.
+type snapshot struct {
+	records map[int64]Record
+	hashes  map[int64]recordHash
+	digest  recordHash
+}
+
+func newSnapshot(records []Record) *snapshot {
+	s := &snapshot{
+		records: map[int64]Record{},
+		hashes:  map[int64]recordHash{},
+	}
+	ids := make([]int64, 0, len(records))
+	for _, r := range records {
+		s.records[r.RecordID] = r
+		s.hashes[r.RecordID] = hashRecord(&r)
+		ids = append(ids, r.RecordID)
+	}
+
+	// The API does not list records in a fixed order.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	h := sha256.New()
+	for _, id := range ids {
+		sum := s.hashes[id]
+		h.Write(sum[:])
+	}
+	copy(s.digest[:], h.Sum(nil))
+	return s
+}
+
+// diff returns the events that turn the snapshot into next, ordered by
+// record ID.
+func (s *snapshot) diff(next *snapshot) []Event {
+	if s.digest == next.digest {
+		return nil
+	}
+	var events []Event
+	for id, r := range next.records {
+		old, ok := s.records[id]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventAdded, Record: r})
+		case s.hashes[id] != next.hashes[id]:
+			previous := old
+			events = append(events, Event{Type: EventModified, Record: r, Previous: &previous})
+		}
+	}
+	for id, r := range s.records {
+		if _, ok := next.records[id]; !ok {
+			events = append(events, Event{Type: EventRemoved, Record: r})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Record.RecordID < events[j].Record.RecordID
+	})
+	return events
+}
+
+// Watch polls the records of a domain every interval and sends an Event
+// for each record added, modified or removed. The records held when
+// watching starts are sent first, as added. Polls that see no change send
+// nothing, and failed polls send an EventError.
+//
+// Watch lists the records before returning, and returns the error if
+// that fails, or if interval is not positive. The channel is closed when
+// ctx is done. Polls always go to the API, not to the Client's Cache, so
+// that changes made elsewhere are seen.
+func (c *Client) Watch(ctx context.Context, domainID string, interval time.Duration) (<-chan Event, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Watch interval must be positive, not %s", interval)
+	}
+	records, err := c.listRecords(domainID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		send := func(batch []Event) bool {
+			for _, e := range batch {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		current := newSnapshot(nil)
+		next := newSnapshot(records)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if !send(current.diff(next)) {
+				return
+			}
+			current = next
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			records, err := c.listRecords(domainID, nil)
+			if err != nil {
+				if !send([]Event{{Type: EventError, Err: err}}) {
+					return
+				}
+				continue
+			}
+			next = newSnapshot(records)
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,89 @@
+package dnsmadeeasy
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	select {
+	case e, ok := <-events:
+		if !ok {
+			t.Fatalf("events closed")
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for event")
+	}
+	return Event{}
+}
+
+func TestClient_Watch(t *testing.T) {
+	api, client, domainID := newFakeDomain(t,
+		Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300},
+		Record{RecordID: 2, Name: "mail", Type: "A", Value: "2.2.2.2", TTL: 300},
+	)
+	defer api.Close()
+	id, _ := strconv.ParseInt(domainID, 10, 64)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.Watch(ctx, domainID, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The records held at the start are sent as added.
+	for _, id := range []int64{1, 2} {
+		if e := nextEvent(t, events); e.Type != EventAdded || e.Record.RecordID != id {
+			t.Fatalf("bad event: %v %#v", e.Type, e.Record)
+		}
+	}
+
+	// Changes made elsewhere are seen on the next poll.
+	api.AddRecord(id, Record{RecordID: 1, Name: "www", Type: "A", Value: "1.1.1.9", TTL: 300})
+	api.RemoveRecord(id, 2)
+	api.AddRecord(id, Record{RecordID: 3, Name: "new", Type: "A", Value: "3.3.3.3"})
+
+	e := nextEvent(t, events)
+	if e.Type != EventModified || e.Record.Value != "1.1.1.9" || e.Previous.Value != "1.1.1.1" {
+		t.Fatalf("bad modified event: %#v", e)
+	}
+	if e := nextEvent(t, events); e.Type != EventRemoved || e.Record.RecordID != 2 {
+		t.Fatalf("bad removed event: %#v", e)
+	}
+	if e := nextEvent(t, events); e.Type != EventAdded || e.Record.RecordID != 3 {
+		t.Fatalf("bad added event: %#v", e)
+	}
+
+	// Unchanged polls send nothing.
+	select {
+	case e := <-events:
+		t.Fatalf("spurious event: %#v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_WatchError(t *testing.T) {
+	client := makeClient(t)
+	client.URL = "http://127.0.0.1:1"
+	if _, err := client.Watch(context.Background(), "870073", time.Second); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestClient_WatchInterval(t *testing.T) {
+	api, client, domainID := newFakeDomain(t)
+	defer api.Close()
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := client.Watch(context.Background(), domainID, interval); err == nil {
+			t.Fatalf("expected an error for interval %s", interval)
+		}
+	}
+}
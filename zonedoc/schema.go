@@ -0,0 +1,259 @@
+package zonedoc
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+// Error is a problem found in a document.
+type Error struct {
+	Line   int
+	Column int
+
+	// Path is the location of the problem within the document, for
+	// example records[3].ttl.
+	Path string
+
+	Msg string
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Msg)
+}
+
+// ErrorList is all the problems found in a document.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// kind is the type of value a field holds.
+type kind int
+
+const (
+	kString kind = iota
+	kInt
+	kBool
+	kStringList
+	kObject
+	kList
+)
+
+func (k kind) String() string {
+	return [...]string{"string", "integer", "boolean", "list of strings",
+		"mapping", "list"}[k]
+}
+
+// field is an entry of a schema. Fields of kind kObject have their own
+// schema, and fields of kind kList hold mappings of that schema.
+type field struct {
+	kind     kind
+	required bool
+	schema   schema
+}
+
+type schema map[string]field
+
+var monitoringSchema = schema{
+	"protocol":        {kind: kInt, required: true},
+	"port":            {kind: kInt},
+	"sensitivity":     {kind: kInt},
+	"autoFailover":    {kind: kBool},
+	"ips":             {kind: kStringList},
+	"contactList":     {kind: kString},
+	"httpFqdn":        {kind: kString},
+	"httpFile":        {kind: kString},
+	"httpQueryString": {kind: kString},
+}
+
+var recordSchema = schema{
+	"name":         {kind: kString, required: true},
+	"type":         {kind: kString, required: true},
+	"value":        {kind: kString, required: true},
+	"ttl":          {kind: kInt},
+	"gtdLocation":  {kind: kString},
+	"mxLevel":      {kind: kInt},
+	"priority":     {kind: kInt},
+	"weight":       {kind: kInt},
+	"port":         {kind: kInt},
+	"caaTag":       {kind: kString},
+	"caaCritical":  {kind: kBool},
+	"redirectType": {kind: kString},
+	"title":        {kind: kString},
+	"keywords":     {kind: kString},
+	"description":  {kind: kString},
+	"hardLink":     {kind: kBool},
+	"dynamicDns":   {kind: kBool},
+	"monitor":      {kind: kBool},
+	"failover":     {kind: kBool},
+	"monitoring":   {kind: kObject, schema: monitoringSchema},
+}
+
+// schemas holds the schema of each supported apiVersion.
+var schemas = map[string]schema{
+	APIVersion: {
+		"apiVersion": {kind: kString, required: true},
+		"kind":       {kind: kString, required: true},
+		"domain": {kind: kObject, required: true, schema: schema{
+			"name":       {kind: kString, required: true},
+			"gtdEnabled": {kind: kBool},
+		}},
+		"records": {kind: kList, schema: recordSchema},
+	},
+}
+
+// checkSchema checks the document node against the schema of its
+// apiVersion.
+func checkSchema(node *yaml.Node) ErrorList {
+	if node.Kind != yaml.MappingNode {
+		return ErrorList{{Line: node.Line, Column: node.Column,
+			Msg: "document must be a mapping"}}
+	}
+
+	version := mappingValue(node, "apiVersion")
+	if version == nil {
+		return ErrorList{{Line: node.Line, Column: node.Column,
+			Msg: "apiVersion is required"}}
+	}
+	s, ok := schemas[version.Value]
+	if !ok {
+		return ErrorList{{Line: version.Line, Column: version.Column,
+			Path: "apiVersion", Msg: fmt.Sprintf("unsupported version %q", version.Value)}}
+	}
+
+	errs := s.check(node, "")
+	if k := mappingValue(node, "kind"); k != nil && k.Value != Kind {
+		errs = append(errs, &Error{Line: k.Line, Column: k.Column, Path: "kind",
+			Msg: fmt.Sprintf("kind must be %s", Kind)})
+	}
+	return errs
+}
+
+// check checks a mapping node against the schema.
+func (s schema) check(node *yaml.Node, path string) ErrorList {
+	var errs ErrorList
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		p := joinPath(path, key.Value)
+		f, ok := s[key.Value]
+		if !ok {
+			errs = append(errs, &Error{Line: key.Line, Column: key.Column,
+				Path: p, Msg: "unknown field"})
+			continue
+		}
+		if seen[key.Value] {
+			errs = append(errs, &Error{Line: key.Line, Column: key.Column,
+				Path: p, Msg: "duplicate field"})
+		}
+		seen[key.Value] = true
+		errs = append(errs, f.check(value, p)...)
+	}
+
+	for name, f := range s {
+		if f.required && !seen[name] {
+			errs = append(errs, &Error{Line: node.Line, Column: node.Column,
+				Path: path, Msg: fmt.Sprintf("%s is required", name)})
+		}
+	}
+	errs.sort()
+	return errs
+}
+
+// check checks a value node holds the kind of the field.
+func (f field) check(node *yaml.Node, path string) ErrorList {
+	wrong := ErrorList{{Line: node.Line, Column: node.Column, Path: path,
+		Msg: fmt.Sprintf("expected %s", f.kind)}}
+
+	switch f.kind {
+	case kString:
+		// Unquoted numbers are accepted as strings, for values such as
+		// addresses written without quotes.
+		if node.Kind != yaml.ScalarNode || node.Tag == "!!bool" {
+			return wrong
+		}
+	case kInt:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return wrong
+		}
+	case kBool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return wrong
+		}
+	case kStringList:
+		if node.Kind != yaml.SequenceNode {
+			return wrong
+		}
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return ErrorList{{Line: item.Line, Column: item.Column,
+					Path: fmt.Sprintf("%s[%d]", path, i), Msg: "expected string"}}
+			}
+		}
+	case kObject:
+		if node.Kind != yaml.MappingNode {
+			return wrong
+		}
+		return f.schema.check(node, path)
+	case kList:
+		if node.Kind != yaml.SequenceNode {
+			return wrong
+		}
+		var errs ErrorList
+		for i, item := range node.Content {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			if item.Kind != yaml.MappingNode {
+				errs = append(errs, &Error{Line: item.Line, Column: item.Column,
+					Path: p, Msg: "expected mapping"})
+				continue
+			}
+			errs = append(errs, f.schema.check(item, p)...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// sort orders the errors by their position in the document.
+func (l ErrorList) sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].Line != l[j].Line {
+			return l[i].Line < l[j].Line
+		}
+		if l[i].Column != l[j].Column {
+			return l[i].Column < l[j].Column
+		}
+		return l[i].Msg < l[j].Msg
+	})
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func recordPath(i int) string {
+	return fmt.Sprintf("records[%d]", i)
+}
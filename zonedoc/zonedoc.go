@@ -0,0 +1,287 @@
+// Package zonedoc reads and writes zones as versioned YAML or JSON
+// documents, for keeping zones under version control.
+//
+// A document looks like:
+//
+//	apiVersion: dnsmadeeasy/v1
+//	kind: Zone
+//	domain:
+//	  name: example.com
+//	  gtdEnabled: true
+//	records:
+//	  - name: www
+//	    type: A
+//	    value: 1.1.1.1
+//	    ttl: 300
+//	    gtdLocation: US_EAST
+//
+// Records are written in a stable order, and fields are written in a fixed
+// order with empty fields left out, so that changes to a zone show up as
+// small diffs.
+package zonedoc
+
+import (
+	"bytes"
+	"encoding/json"
+	dme "github.com/soniah/dnsmadeeasy"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+// APIVersion is the version of the document format written by this
+// package.
+const APIVersion = "dnsmadeeasy/v1"
+
+// Kind is the kind of a zone document.
+const Kind = "Zone"
+
+// Document is a zone and its records.
+type Document struct {
+	APIVersion string   `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Domain     Domain   `json:"domain" yaml:"domain"`
+	Records    []Record `json:"records" yaml:"records"`
+}
+
+// Domain is the metadata of a zone.
+type Domain struct {
+	Name       string `json:"name" yaml:"name"`
+	GTDEnabled bool   `json:"gtdEnabled,omitempty" yaml:"gtdEnabled,omitempty"`
+}
+
+// Record is a record of a zone. It holds the fields of dnsmadeeasy.Record
+// that describe the record, leaving out those set by the API.
+type Record struct {
+	Name  string `json:"name" yaml:"name"`
+	Type  string `json:"type" yaml:"type"`
+	Value string `json:"value" yaml:"value"`
+	TTL   int64  `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+
+	GTDLocation string `json:"gtdLocation,omitempty" yaml:"gtdLocation,omitempty"`
+
+	MXLevel  int64 `json:"mxLevel,omitempty" yaml:"mxLevel,omitempty"`
+	Priority int64 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Weight   int64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Port     int64 `json:"port,omitempty" yaml:"port,omitempty"`
+
+	CAATag      string `json:"caaTag,omitempty" yaml:"caaTag,omitempty"`
+	CAACritical bool   `json:"caaCritical,omitempty" yaml:"caaCritical,omitempty"`
+
+	RedirectType string `json:"redirectType,omitempty" yaml:"redirectType,omitempty"`
+	Title        string `json:"title,omitempty" yaml:"title,omitempty"`
+	Keywords     string `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	HardLink     bool   `json:"hardLink,omitempty" yaml:"hardLink,omitempty"`
+
+	DynamicDNS bool `json:"dynamicDns,omitempty" yaml:"dynamicDns,omitempty"`
+	Monitor    bool `json:"monitor,omitempty" yaml:"monitor,omitempty"`
+	Failover   bool `json:"failover,omitempty" yaml:"failover,omitempty"`
+
+	// Monitoring is the configuration of the record's monitor, if known.
+	Monitoring *Monitoring `json:"monitoring,omitempty" yaml:"monitoring,omitempty"`
+}
+
+// Monitoring is the monitor configuration of a record. The contact list is
+// referred to by name, so documents can move between accounts.
+type Monitoring struct {
+	Protocol        int64    `json:"protocol" yaml:"protocol"`
+	Port            int64    `json:"port,omitempty" yaml:"port,omitempty"`
+	Sensitivity     int64    `json:"sensitivity,omitempty" yaml:"sensitivity,omitempty"`
+	AutoFailover    bool     `json:"autoFailover,omitempty" yaml:"autoFailover,omitempty"`
+	IPs             []string `json:"ips,omitempty" yaml:"ips,omitempty"`
+	ContactList     string   `json:"contactList,omitempty" yaml:"contactList,omitempty"`
+	HTTPFqdn        string   `json:"httpFqdn,omitempty" yaml:"httpFqdn,omitempty"`
+	HTTPFile        string   `json:"httpFile,omitempty" yaml:"httpFile,omitempty"`
+	HTTPQueryString string   `json:"httpQueryString,omitempty" yaml:"httpQueryString,omitempty"`
+}
+
+// New returns a document for the domain holding records.
+func New(domain string, records []dme.Record) *Document {
+	doc := &Document{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Domain:     Domain{Name: domain},
+	}
+	for _, r := range records {
+		doc.Records = append(doc.Records, FromRecord(&r))
+	}
+	doc.Sort()
+	return doc
+}
+
+// FromRecord returns the document form of a record.
+func FromRecord(r *dme.Record) Record {
+	location := r.GtdLocation
+	if location == string(dme.GTDDefault) {
+		location = ""
+	}
+	name := r.Name
+	if name == "@" {
+		name = ""
+	}
+	return Record{
+		Name:         name,
+		Type:         r.Type,
+		Value:        r.Value,
+		TTL:          r.TTL,
+		GTDLocation:  location,
+		MXLevel:      r.MXLevel,
+		Priority:     r.Priority,
+		Weight:       r.Weight,
+		Port:         r.Port,
+		CAATag:       r.CaaType,
+		CAACritical:  r.IssuerCritical&dme.CAACritical != 0,
+		RedirectType: r.RedirectType,
+		Title:        r.Title,
+		Keywords:     r.Keywords,
+		Description:  r.Description,
+		HardLink:     r.HardLink,
+		DynamicDNS:   r.DynamicDNS,
+		Monitor:      r.Monitor,
+		Failover:     r.Failover,
+	}
+}
+
+// Record returns the record as a dnsmadeeasy.Record.
+func (r *Record) Record() dme.Record {
+	location := r.GTDLocation
+	if location == "" {
+		location = string(dme.GTDDefault)
+	}
+	var critical int64
+	if r.CAACritical {
+		critical = dme.CAACritical
+	}
+	return dme.Record{
+		Name:           r.Name,
+		Type:           r.Type,
+		Value:          r.Value,
+		TTL:            r.TTL,
+		GtdLocation:    location,
+		MXLevel:        r.MXLevel,
+		Priority:       r.Priority,
+		Weight:         r.Weight,
+		Port:           r.Port,
+		CaaType:        r.CAATag,
+		IssuerCritical: critical,
+		RedirectType:   r.RedirectType,
+		Title:          r.Title,
+		Keywords:       r.Keywords,
+		Description:    r.Description,
+		HardLink:       r.HardLink,
+		DynamicDNS:     r.DynamicDNS,
+		Monitor:        r.Monitor,
+		Failover:       r.Failover,
+	}
+}
+
+// Monitor returns the monitoring configuration as a dnsmadeeasy.Monitor.
+// The contact list is left to be resolved by name.
+func (m *Monitoring) Monitor(r *Record) *dme.Monitor {
+	return &dme.Monitor{
+		Monitor:         r.Monitor,
+		Failover:        r.Failover,
+		AutoFailover:    m.AutoFailover,
+		ProtocolID:      m.Protocol,
+		Port:            m.Port,
+		Sensitivity:     m.Sensitivity,
+		IPs:             m.IPs,
+		ContactListName: m.ContactList,
+		HTTPFqdn:        m.HTTPFqdn,
+		HTTPFile:        m.HTTPFile,
+		HTTPQueryString: m.HTTPQueryString,
+	}
+}
+
+// ToRecords returns the records of the document as dnsmadeeasy.Records.
+func (d *Document) ToRecords() []dme.Record {
+	var records []dme.Record
+	for i := range d.Records {
+		records = append(records, d.Records[i].Record())
+	}
+	return records
+}
+
+// Sort puts the records in a stable order: by name, type, location and
+// value.
+func (d *Document) Sort() {
+	sort.SliceStable(d.Records, func(i, j int) bool {
+		a, b := d.Records[i], d.Records[j]
+		if x, y := strings.ToLower(a.Name), strings.ToLower(b.Name); x != y {
+			return x < y
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.GTDLocation != b.GTDLocation {
+			return a.GTDLocation < b.GTDLocation
+		}
+		return a.Value < b.Value
+	})
+}
+
+// EncodeYAML returns the document as YAML, with records sorted.
+func (d *Document) EncodeYAML() ([]byte, error) {
+	d.Sort()
+	buf := bytes.NewBuffer(nil)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeJSON returns the document as indented JSON, with records sorted.
+func (d *Document) EncodeJSON() ([]byte, error) {
+	d.Sort()
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Decode parses a YAML or JSON document, checking it against the schema
+// of its apiVersion and validating its records. Problems are returned as
+// an ErrorList, each with the line it was found on.
+func Decode(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, ErrorList{{Line: 1, Msg: "document is empty"}}
+	}
+	node := root.Content[0]
+
+	if errs := checkSchema(node); len(errs) > 0 {
+		return nil, errs
+	}
+
+	doc := new(Document)
+	if err := node.Decode(doc); err != nil {
+		return nil, err
+	}
+
+	// Each record is validated as it would be by the client, and its
+	// problems reported at the record's line.
+	var errs ErrorList
+	records := mappingValue(node, "records")
+	for i := range doc.Records {
+		r := doc.Records[i].Record()
+		if err := r.Validate(); err != nil {
+			n := records.Content[i]
+			errs = append(errs, &Error{Line: n.Line, Column: n.Column,
+				Path: recordPath(i), Msg: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return doc, nil
+}
@@ -0,0 +1,146 @@
+package zonedoc
+
+import (
+	dme "github.com/soniah/dnsmadeeasy"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var liveRecords = []dme.Record{
+	{RecordID: 3, Name: "www", Type: "A", Value: "2.2.2.2", TTL: 300, GtdLocation: "EUROPE", Monitor: true, Failover: true},
+	{RecordID: 1, Name: "", Type: "MX", Value: "mail", TTL: 86400, MXLevel: 10, GtdLocation: "DEFAULT"},
+	{RecordID: 2, Name: "www", Type: "A", Value: "1.1.1.1", TTL: 300, GtdLocation: "US_EAST"},
+	{RecordID: 4, Name: "", Type: "CAA", Value: `"letsencrypt.org"`, TTL: 3600, CaaType: "issue", GtdLocation: "DEFAULT"},
+	{RecordID: 5, Name: "go", Type: "HTTPRED", Value: "https://example.net/", TTL: 1800,
+		RedirectType: dme.RedirectPermanent, HardLink: true, GtdLocation: "DEFAULT"},
+}
+
+const expectedYAML = `apiVersion: dnsmadeeasy/v1
+kind: Zone
+domain:
+  name: example.com
+records:
+  - name: ""
+    type: CAA
+    value: '"letsencrypt.org"'
+    ttl: 3600
+    caaTag: issue
+  - name: ""
+    type: MX
+    value: mail
+    ttl: 86400
+    mxLevel: 10
+  - name: go
+    type: HTTPRED
+    value: https://example.net/
+    ttl: 1800
+    redirectType: Standard - 301
+    hardLink: true
+  - name: www
+    type: A
+    value: 2.2.2.2
+    ttl: 300
+    gtdLocation: EUROPE
+    monitor: true
+    failover: true
+  - name: www
+    type: A
+    value: 1.1.1.1
+    ttl: 300
+    gtdLocation: US_EAST
+`
+
+func TestEncodeYAMLStable(t *testing.T) {
+	data, err := New("example.com", liveRecords).EncodeYAML()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(data) != expectedYAML {
+		t.Fatalf("bad yaml:\n%s", data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc := New("example.com", liveRecords)
+	for _, encode := range []func() ([]byte, error){doc.EncodeYAML, doc.EncodeJSON} {
+		data, err := encode()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		back, err := Decode(data)
+		if err != nil {
+			t.Fatalf("err: %v\n%s", err, data)
+		}
+		if !reflect.DeepEqual(back, doc) {
+			t.Fatalf("round trip changed the document:\n%#v\n%#v", back, doc)
+		}
+	}
+
+	// Records keep everything but the fields set by the API.
+	records := doc.ToRecords()
+	if records[4].GtdLocation != "US_EAST" || records[1].MXLevel != 10 {
+		t.Fatalf("bad records: %#v", records)
+	}
+	if records[0].Fields()["caaType"] != "issue" {
+		t.Fatalf("bad caa record: %#v", records[0])
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	doc := `apiVersion: dnsmadeeasy/v1
+kind: Zone
+domain:
+  name: example.com
+records:
+  - name: www
+    type: A
+    value: 1.1.1.1
+    ttl: "300"
+  - name: mail
+    type: A
+    vaule: 1.1.1.2
+  - name: v6
+    type: AAAA
+    value: 1.1.1.3
+`
+	_, err := Decode([]byte(doc))
+	list, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+	want := []string{
+		"line 9: records[0].ttl: expected integer",
+		"line 10: records[1]: value is required",
+		"line 12: records[1].vaule: unknown field",
+	}
+	if len(list) != len(want) {
+		t.Fatalf("bad errors:\n%v", err)
+	}
+	for i, e := range list {
+		if e.Error() != want[i] {
+			t.Fatalf("error %d: %q, want %q", i, e.Error(), want[i])
+		}
+	}
+
+	// Once the schema is satisfied, records are validated by type.
+	doc = strings.Replace(doc, `ttl: "300"`, "ttl: 300", 1)
+	doc = strings.Replace(doc, "vaule", "value", 1)
+	_, err = Decode([]byte(doc))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 13: records[2]: Invalid AAAA record") {
+		t.Fatalf("bad error: %v", err)
+	}
+}
+
+func TestDecodeVersion(t *testing.T) {
+	_, err := Decode([]byte("apiVersion: dnsmadeeasy/v0\nkind: Zone\n"))
+	if err == nil || err.Error() != `line 1: apiVersion: unsupported version "dnsmadeeasy/v0"` {
+		t.Fatalf("bad error: %v", err)
+	}
+
+	_, err = Decode([]byte(`{"apiVersion": "dnsmadeeasy/v1", "kind": "Zones",
+ "domain": {"name": "example.com"}}`))
+	if err == nil || err.Error() != "line 1: kind: kind must be Zone" {
+		t.Fatalf("bad error: %v", err)
+	}
+}